@@ -17,4 +17,13 @@ type Config struct {
 	// if you try to map a numeric value (int, uint or float) to a string, you will get an error (by default),
 	// but this allows you to automatically convert numbers to strings.
 	AutoNumberToStringConversion bool
+	// if a destination field has no matching source field, it will be left at its zero value (by default),
+	// but this allows you to get an error for those unmapped destination fields, unless they are ignored using "-".
+	Strict bool
+	// if a source field is not read by any of the destination fields, it will be silently skipped (by default),
+	// but this allows you to get an error for those unused source fields.
+	StrictSource bool
+	// if it's set, the fields are mapped using their profile tags (e.g. `smapper.db:"user_name"` for the "db" profile)
+	// instead of their smapper tags, fields without a profile tag are mapped using their smapper tags.
+	Profile string
 }
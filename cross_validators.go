@@ -0,0 +1,352 @@
+package smapper
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var crossFieldValidators = map[string]validatorCompiler{
+	"eqfield":  compileEqualsField(true),
+	"nefield":  compileEqualsField(false),
+	"gtfield":  compileCompareField("greater than", func(res int) bool { return res > 0 }),
+	"gtefield": compileCompareField("greater than or equal to", func(res int) bool { return res >= 0 }),
+	"ltfield":  compileCompareField("less than", func(res int) bool { return res < 0 }),
+	"ltefield": compileCompareField("less than or equal to", func(res int) bool { return res <= 0 }),
+
+	// conditional validators
+	"required_if":      compileFieldsMatch(requiredIf),
+	"required_unless":  compileFieldsMatch(requiredUnless),
+	"required_with":    compileFieldsPresence(requiredWith),
+	"required_without": compileFieldsPresence(requiredWithout),
+	"excluded_if":      compileFieldsMatch(excludedIf),
+}
+
+// lookupField returns the value of the field in the given dot separated path (e.g. Address.City),
+// it returns false if the path does not exist, it's unexported, or a pointer in the path is nil.
+func lookupField(parent reflect.Value, path string) (reflect.Value, bool) {
+	v := parent
+
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+
+		v = v.FieldByName(toPascalCase(name))
+		if !v.IsValid() || !v.CanInterface() {
+			return reflect.Value{}, false
+		}
+	}
+
+	return v, true
+}
+
+// lookupFieldType returns the type of the field in the given dot separated path, it returns an error if
+// the path does not exist in parent, or it's unexported.
+// the path is not checked if parent is nil, or the path goes through an interface.
+func lookupFieldType(parent reflect.Type, path string) (reflect.Type, error) {
+	t := parent
+
+	for _, name := range strings.Split(path, ".") {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil || t.Kind() == reflect.Interface {
+			return nil, nil
+		}
+
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("cannot find field %s in %s", path, parent)
+		}
+
+		f, found := t.FieldByName(toPascalCase(name))
+		if !found || !f.IsExported() {
+			return nil, fmt.Errorf("cannot find field %s in %s", path, parent)
+		}
+
+		t = f.Type
+	}
+
+	return t, nil
+}
+
+// compareValues compares a and b, numbers are compared by their values, time.Time values are compared
+// chronologically, and slices, arrays, maps and channels are compared by their length.
+// it returns false if a and b are not comparable.
+func compareValues(a, b reflect.Value) (int, bool) {
+	a, b = reflect.Indirect(a), reflect.Indirect(b)
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	switch {
+	case a.Type() == timeType && b.Type() == timeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return cmp.Compare(a.String(), b.String()), true
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int()), true
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case isNumber(a.Kind()) && isNumber(b.Kind()):
+		return cmp.Compare(toFloat(a), toFloat(b)), true
+	case hasLength(a.Kind()) && hasLength(b.Kind()):
+		return cmp.Compare(a.Len(), b.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func hasLength(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array || k == reflect.Map || k == reflect.Chan
+}
+
+// compileCompareField compiles a validator that compares the field with the field in the param's path,
+// and checks the result using ok.
+func compileCompareField(desc string, ok func(int) bool) validatorCompiler {
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		if _, err := lookupFieldType(parent, param); err != nil {
+			return nil, err
+		}
+
+		return func(field, parent reflect.Value) error {
+			other, found := lookupField(parent, param)
+			if !found {
+				return fmt.Errorf("cannot find field %s", param)
+			}
+
+			res, comparable := compareValues(field, other)
+			if !comparable {
+				return fmt.Errorf("cannot compare with %s", param)
+			}
+
+			if !ok(res) {
+				return fmt.Errorf("want value %s %s", desc, param)
+			}
+
+			return nil
+		}, nil
+	}
+}
+
+// compileEqualsField compiles eqfield (want is true) and nefield (want is false).
+func compileEqualsField(want bool) validatorCompiler {
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		if _, err := lookupFieldType(parent, param); err != nil {
+			return nil, err
+		}
+
+		return func(field, parent reflect.Value) error {
+			other, found := lookupField(parent, param)
+			if !found {
+				return fmt.Errorf("cannot find field %s", param)
+			}
+
+			if equalsField(field, other) == want {
+				return nil
+			}
+
+			if want {
+				return fmt.Errorf("want value equal to %s", param)
+			}
+
+			return fmt.Errorf("want value not equal to %s", param)
+		}, nil
+	}
+}
+
+func equalsField(field, other reflect.Value) bool {
+	if res, ok := compareValues(field, other); ok && !hasLength(reflect.Indirect(field).Kind()) {
+		return res == 0
+	}
+
+	return reflect.DeepEqual(field.Interface(), other.Interface())
+}
+
+// fieldValue is a pair of a field path and a value in the param of a conditional validator.
+type fieldValue struct {
+	path  string
+	value string
+}
+
+// parseFieldValues parses the space separated pairs of field paths and values (e.g. "Type card Currency USD"),
+// it returns an error if the param is not a list of pairs, a field does not exist, or a value is not valid
+// for its field's type.
+func parseFieldValues(parent reflect.Type, param string) ([]fieldValue, error) {
+	params := splitParams(param)
+	if len(params) == 0 || len(params)%2 != 0 {
+		return nil, fmt.Errorf("invalid param %s, want pairs of fields and values", param)
+	}
+
+	res := make([]fieldValue, 0, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		t, err := lookupFieldType(parent, params[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if t = knownType(t); t != nil {
+			if _, err := equalsParam(reflect.Zero(t), params[i+1]); err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, fieldValue{path: params[i], value: params[i+1]})
+	}
+
+	return res, nil
+}
+
+// fieldsMatch checks if all the fields have their values.
+func fieldsMatch(parent reflect.Value, fields []fieldValue) (bool, error) {
+	for _, f := range fields {
+		other, found := lookupField(parent, f.path)
+		if !found {
+			return false, fmt.Errorf("cannot find field %s", f.path)
+		}
+
+		// nil pointers don't match any value
+		other = reflect.Indirect(other)
+		if !other.IsValid() {
+			return false, nil
+		}
+
+		equal, err := equalsParam(other, f.value)
+		if err != nil || !equal {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// compileFieldsMatch compiles the conditional validators whose params are pairs of fields and values,
+// fn checks the field using the result of matching the fields with their values.
+func compileFieldsMatch(fn func(field reflect.Value, match bool, param string) error) validatorCompiler {
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		fields, err := parseFieldValues(parent, param)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(field, parent reflect.Value) error {
+			match, err := fieldsMatch(parent, fields)
+			if err != nil {
+				return err
+			}
+
+			return fn(field, match, param)
+		}, nil
+	}
+}
+
+// compileFieldsPresence compiles the conditional validators whose params are space separated fields,
+// fn checks the field using the fields' values.
+func compileFieldsPresence(fn func(field reflect.Value, others []reflect.Value, param string) error) validatorCompiler {
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		paths := splitParams(param)
+		if len(paths) == 0 {
+			return nil, errors.New("want at least one field")
+		}
+
+		for _, path := range paths {
+			if _, err := lookupFieldType(parent, path); err != nil {
+				return nil, err
+			}
+		}
+
+		return func(field, parent reflect.Value) error {
+			others := make([]reflect.Value, 0, len(paths))
+
+			for _, path := range paths {
+				other, found := lookupField(parent, path)
+				if !found {
+					return fmt.Errorf("cannot find field %s", path)
+				}
+
+				others = append(others, other)
+			}
+
+			return fn(field, others, param)
+		}, nil
+	}
+}
+
+// requiredIf checks if the field exists when all the fields in param have their values
+// (e.g. required_if=Type card).
+func requiredIf(field reflect.Value, match bool, param string) error {
+	if match && !exists(field) {
+		return fmt.Errorf("value is required if %s", param)
+	}
+
+	return nil
+}
+
+// requiredUnless checks if the field exists unless all the fields in param have their values
+// (e.g. required_unless=Type cash).
+func requiredUnless(field reflect.Value, match bool, param string) error {
+	if !match && !exists(field) {
+		return fmt.Errorf("value is required unless %s", param)
+	}
+
+	return nil
+}
+
+// excludedIf checks if the field is zero value when all the fields in param have their values
+// (e.g. excluded_if=Type cash).
+func excludedIf(field reflect.Value, match bool, param string) error {
+	if match && exists(field) {
+		return fmt.Errorf("value is not allowed if %s", param)
+	}
+
+	return nil
+}
+
+// requiredWith checks if the field exists when any of the fields in param exists
+// (e.g. required_with=Street City).
+func requiredWith(field reflect.Value, others []reflect.Value, param string) error {
+	for _, other := range others {
+		if exists(other) && !exists(field) {
+			return fmt.Errorf("value is required with %s", param)
+		}
+	}
+
+	return nil
+}
+
+// requiredWithout checks if the field exists when any of the fields in param is missing
+// (e.g. required_without=Phone).
+func requiredWithout(field reflect.Value, others []reflect.Value, param string) error {
+	for _, other := range others {
+		if !exists(other) && !exists(field) {
+			return fmt.Errorf("value is required without %s", param)
+		}
+	}
+
+	return nil
+}
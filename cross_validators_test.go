@@ -0,0 +1,181 @@
+package smapper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_crossFieldValidators(t *testing.T) {
+	t.Parallel()
+
+	type limits struct {
+		Max int
+	}
+
+	type src struct {
+		Password        string
+		PasswordConfirm string
+		OldPassword     string
+		StartDate       time.Time
+		EndDate         time.Time
+		Min             int
+		Value           uint
+		Limits          *limits
+	}
+
+	type dst struct {
+		PasswordConfirm string    `smapper:",eqfield=Password,nefield=OldPassword"`
+		EndDate         time.Time `smapper:",gtfield=StartDate"`
+		Value           int       `smapper:",gtefield=Min,ltefield=Limits.Max"`
+	}
+
+	now := time.Now()
+	valid := src{
+		Password:        "secret",
+		PasswordConfirm: "secret",
+		OldPassword:     "old",
+		StartDate:       now,
+		EndDate:         now.Add(time.Hour),
+		Min:             1,
+		Value:           5,
+		Limits:          &limits{Max: 5},
+	}
+
+	type testCase struct {
+		name      string
+		modify    func(s *src)
+		expectErr bool
+	}
+
+	tests := []testCase{
+		{"all good", func(s *src) {}, false},
+		{"passwords do not match", func(s *src) { s.PasswordConfirm = "wrong" }, true},
+		{"new password is the old password", func(s *src) { s.OldPassword = "secret" }, true},
+		{"end date is before start date", func(s *src) { s.EndDate = now.Add(-time.Hour) }, true},
+		{"value is less than min", func(s *src) { s.Min = 6 }, true},
+		{"value is greater than nested max", func(s *src) { s.Limits.Max = 4 }, true},
+		{"nested path is nil", func(s *src) { s.Limits = nil }, true},
+	}
+
+	mapper := New()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := valid
+			s.Limits = &limits{Max: valid.Limits.Max}
+			test.modify(&s)
+
+			if test.expectErr {
+				assert.Error(t, mapper.Map(s, &dst{}))
+			} else {
+				assert.NoError(t, mapper.Map(s, &dst{}))
+			}
+		})
+	}
+}
+
+func TestMap_CustomCrossFieldValidators(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Currency string
+		Amount   int
+	}
+
+	type dst struct {
+		Amount int `smapper:",max_for_currency=100"`
+	}
+
+	mapper := New(WithValidators(NewCrossFieldValidator("max_for_currency",
+		func(field, parent reflect.Value, param string) bool {
+			if parent.FieldByName("Currency").String() != "USD" {
+				return true
+			}
+
+			max, _ := strconv.ParseInt(param, 10, 64)
+
+			return field.Int() <= max
+		})))
+
+	assert.NoError(t, mapper.Map(src{"USD", 100}, &dst{}))
+	assert.NoError(t, mapper.Map(src{"EUR", 200}, &dst{}))
+	assert.Error(t, mapper.Map(src{"USD", 200}, &dst{}))
+}
+
+func Test_conditionalValidators(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Type       string
+		CardNumber string
+		CashierID  int
+		Street     string
+		City       string
+		Email      string
+		Phone      string
+	}
+
+	type dst struct {
+		CardNumber string `smapper:",required_if=Type card"`
+		CashierID  int    `smapper:",required_unless=Type card,excluded_if=Type card"`
+		City       string `smapper:",required_with=Street"`
+		Email      string `smapper:",required_without=Phone"`
+	}
+
+	type testCase struct {
+		name      string
+		src       src
+		expectErr bool
+	}
+
+	tests := []testCase{
+		{"card payment", src{Type: "card", CardNumber: "4111", Phone: "0912"}, false},
+		{"card payment without card number", src{Type: "card", Phone: "0912"}, true},
+		{"card payment with cashier", src{Type: "card", CardNumber: "4111", CashierID: 1, Phone: "0912"}, true},
+		{"cash payment", src{Type: "cash", CashierID: 1, Phone: "0912"}, false},
+		{"cash payment without cashier", src{Type: "cash", Phone: "0912"}, true},
+		{"street without city", src{Type: "cash", CashierID: 1, Street: "Pine St", Phone: "0912"}, true},
+		{"street with city", src{Type: "cash", CashierID: 1, Street: "Pine St", City: "NY", Phone: "0912"}, false},
+		{"neither phone nor email", src{Type: "cash", CashierID: 1}, true},
+		{"email without phone", src{Type: "cash", CashierID: 1, Email: "admin@example.com"}, false},
+	}
+
+	mapper := New()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.expectErr {
+				assert.Error(t, mapper.Map(test.src, &dst{}))
+			} else {
+				assert.NoError(t, mapper.Map(test.src, &dst{}))
+			}
+		})
+	}
+
+	type optionalSrc struct {
+		Type       *string
+		CardNumber string
+		CashierID  int
+	}
+
+	type optionalDst struct {
+		CardNumber string `smapper:",required_if=Type card"`
+		CashierID  int    `smapper:",required_unless=Type card,excluded_if=Type card"`
+	}
+
+	card := "card"
+
+	// nil pointers don't match any value
+	assert.NoError(t, mapper.Map(optionalSrc{CashierID: 1}, &optionalDst{}))
+	assert.Error(t, mapper.Map(optionalSrc{}, &optionalDst{}))
+	assert.NoError(t, mapper.Map(optionalSrc{Type: &card, CardNumber: "4111"}, &optionalDst{}))
+	assert.Error(t, mapper.Map(optionalSrc{Type: &card}, &optionalDst{}))
+
+	type invalid struct {
+		CardNumber string `smapper:",required_if=Type"`
+	}
+	assert.Error(t, mapper.Map(src{}, &invalid{}), "should have error because the param is not a list of pairs")
+}
@@ -0,0 +1,98 @@
+package smapper
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// checkDefaults checks if the fields that have defaults set using WithDefault exist in t and are exported,
+// so a misspelled field name is reported instead of being ignored.
+func (m *Mapper) checkDefaults(t reflect.Type) error {
+	names := make([]string, 0, len(m.defaults[t]))
+	for name := range m.defaults[t] {
+		names = append(names, name)
+	}
+
+	// the fields are checked in order, so the same error is returned for the same defaults
+	slices.Sort(names)
+
+	for _, name := range names {
+		// promoted fields are not supported, since the defaults are looked up by the top-level fields
+		f, found := t.FieldByName(name)
+		if !found || !f.IsExported() || len(f.Index) > 1 {
+			return &ConfigError{parentType: t, fieldName: name, msg: "no exported field for the default value"}
+		}
+	}
+
+	return nil
+}
+
+// defaultValue returns the default value of the given field converted to its type, the default set
+// using WithDefault takes precedence over the literal in the field's tag. it returns an invalid value if
+// the field has no default.
+func (m *Mapper) defaultValue(parent reflect.Type, field reflect.StructField, literal *string) (reflect.Value, error) {
+	if value, found := m.defaults[parent][field.Name]; found {
+		return m.convertDefault(parent, field, reflect.ValueOf(value))
+	}
+
+	if literal != nil {
+		return m.convertDefault(parent, field, reflect.ValueOf(*literal))
+	}
+
+	return reflect.Value{}, nil
+}
+
+// convertDefault converts the default value into the field's type, strings are parsed using the
+// same rules that are used to convert strings to numbers, regardless of AutoStringToNumberConversion.
+func (m *Mapper) convertDefault(parent reflect.Type, field reflect.StructField, value reflect.Value) (reflect.Value, error) {
+	dst := reflect.New(field.Type).Elem()
+
+	if value.Type().AssignableTo(field.Type) {
+		dst.Set(value)
+
+		return dst, nil
+	}
+
+	target := dst
+	if field.Type.Kind() == reflect.Ptr {
+		dst.Set(reflect.New(field.Type.Elem()))
+		target = dst.Elem()
+	}
+
+	invalid := &FieldError{
+		value: NewFieldValue(value, parent, field.Name),
+		msg:   fmt.Sprintf("invalid default value %v for %s", value, field.Type),
+	}
+
+	if value.Type().AssignableTo(target.Type()) {
+		target.Set(value)
+
+		return dst, nil
+	}
+
+	if target.Kind() == reflect.Bool && value.Kind() == reflect.String {
+		b, err := strconv.ParseBool(value.String())
+		if err != nil {
+			return reflect.Value{}, invalid
+		}
+
+		target.SetBool(b)
+
+		return dst, nil
+	}
+
+	if !isNumber(target.Kind()) && target.Kind() != reflect.String {
+		return reflect.Value{}, invalid
+	}
+
+	conv := &Mapper{Config: Config{AutoStringToNumberConversion: true, AutoNumberToStringConversion: true}}
+
+	_, err := conv.convert(NewFieldValue(value, parent, field.Name), NewFieldValue(target, parent, field.Name))
+	if err != nil {
+		return reflect.Value{}, invalid
+	}
+
+	return dst, nil
+}
@@ -2,6 +2,8 @@ package smapper
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 type Error struct {
@@ -27,23 +29,177 @@ func (e *FieldError) Error() string {
 type ValidationError struct {
 	value         FieldValue
 	validatorName string
+	param         string
+	// err describes why the validator is failed, it's nil if the validator cannot describe it (e.g. ValidatorFunc)
+	err error
+	// structLevel is true if the error is returned by a struct-level validator
+	structLevel bool
+	// message is the template of the user-facing message, and key is its translation key
+	message string
+	key     string
+}
+
+// Message returns the user-facing message of the error, which is the failed validator's message template
+// (see WithMessages) whose placeholders are replaced (e.g. "Age must be at least 18").
+// the message of a struct-level validation error is the error returned by the struct validator.
+func (e *ValidationError) Message() string {
+	if e.structLevel {
+		return e.err.Error()
+	}
+
+	return renderMessage(e.message, e)
+}
+
+// Translate returns the message of the error in the given locale, it returns Message() if tr has no
+// translation for the error.
+func (e *ValidationError) Translate(tr Translator, locale string) string {
+	if e.structLevel || tr == nil {
+		return e.Message()
+	}
+
+	template, found := tr.Translate(locale, e.key)
+	if !found {
+		return e.Message()
+	}
+
+	return renderMessage(template, e)
 }
 
 func (e *ValidationError) Error() string {
-	return fmt.Sprintf("smapper: validator %s failed for %s.%s",
+	if e.structLevel {
+		return fmt.Sprintf("smapper: %s failed for %s, %s",
+			e.validatorName,
+			e.value.path,
+			e.err.Error())
+	}
+
+	msg := fmt.Sprintf("smapper: validator %s failed for %s.%s",
 		e.validatorName,
 		e.value.ParentType.Name(),
 		e.value.FieldName)
+	if e.err != nil {
+		msg += ", " + e.err.Error()
+	}
+
+	return msg
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
 }
 
 type CallbackError struct {
 	value FieldValue
 	msg   string
+	// callbackName and step are the name and the position (starting from 1) of the failed callback in the field's callbacks
+	callbackName string
+	step         int
+	err          error
 }
 
 func (e *CallbackError) Error() string {
-	return fmt.Sprintf("smapper: callback execution failed for %s.%s, %s",
+	// computed fields have no steps
+	if e.step == 0 {
+		return fmt.Sprintf("smapper: compute %s failed for %s.%s, %s",
+			e.callbackName,
+			e.value.ParentType.Name(),
+			e.value.FieldName,
+			e.msg)
+	}
+
+	return fmt.Sprintf("smapper: callback execution failed for %s.%s, step %d (%s), %s",
 		e.value.ParentType.Name(),
 		e.value.FieldName,
+		e.step,
+		e.callbackName,
+		e.msg)
+}
+
+// Callback returns the name of the failed callback (or compute function).
+func (e *CallbackError) Callback() string {
+	return e.callbackName
+}
+
+// Step returns the position of the failed callback in the field's callbacks, starting from 1, it's 0 if a compute
+// function is failed.
+func (e *CallbackError) Step() int {
+	return e.step
+}
+
+func (e *CallbackError) Unwrap() error {
+	return e.err
+}
+
+// HookError is returned when a BeforeMap or an AfterMap hook fails, it wraps the hook's error.
+type HookError struct {
+	hook    string
+	srcType reflect.Type
+	// path is the location of the destination struct (e.g. Order.Customer)
+	path string
+	err  error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("smapper: %s failed for %s (from %s), %s",
+		e.hook,
+		e.path,
+		e.srcType.Name(),
+		e.err.Error())
+}
+
+func (e *HookError) Unwrap() error {
+	return e.err
+}
+
+type UnmappedFieldsError struct {
+	srcType      reflect.Type
+	dstType      reflect.Type
+	unmapped     []string
+	unusedSource []string
+}
+
+// UnmappedFields returns the destination fields that have no matching source field.
+func (e *UnmappedFieldsError) UnmappedFields() []string {
+	return e.unmapped
+}
+
+// UnusedSourceFields returns the source fields that are not read by any of the destination fields.
+func (e *UnmappedFieldsError) UnusedSourceFields() []string {
+	return e.unusedSource
+}
+
+func (e *UnmappedFieldsError) Error() string {
+	var details []string
+
+	if len(e.unmapped) > 0 {
+		details = append(details, fmt.Sprintf("unmapped destination fields: %s", strings.Join(e.unmapped, ", ")))
+	}
+
+	if len(e.unusedSource) > 0 {
+		details = append(details, fmt.Sprintf("unused source fields: %s", strings.Join(e.unusedSource, ", ")))
+	}
+
+	return fmt.Sprintf("smapper: strict mapping failed for %s to %s, %s",
+		e.srcType.Name(),
+		e.dstType.Name(),
+		strings.Join(details, "; "))
+}
+
+// ConfigError is returned when a mapping cannot be planned because of an invalid configuration
+// (e.g. an invalid validator param like len=abc).
+type ConfigError struct {
+	parentType reflect.Type
+	fieldName  string
+	msg        string
+}
+
+func (e *ConfigError) Error() string {
+	if e.parentType == nil {
+		return fmt.Sprintf("smapper: invalid configuration, %s", e.msg)
+	}
+
+	return fmt.Sprintf("smapper: invalid configuration for %s.%s, %s",
+		e.parentType.Name(),
+		e.fieldName,
 		e.msg)
 }
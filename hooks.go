@@ -0,0 +1,108 @@
+package smapper
+
+import "reflect"
+
+// BeforeMapper is implemented by the destination types that prepare themselves before being mapped,
+// BeforeMap receives the source struct, and it's called before any of the fields is mapped.
+type BeforeMapper interface {
+	BeforeMap(src any) error
+}
+
+// AfterMapper is implemented by the destination types that finish their mapping themselves (e.g. normalizing
+// or denormalizing their fields), AfterMap receives the source struct, and it's called after all the fields
+// (including the nested structs) are mapped, and before the struct validators are executed.
+type AfterMapper interface {
+	AfterMap(src any) error
+}
+
+var (
+	beforeMapperType = reflect.TypeOf((*BeforeMapper)(nil)).Elem()
+	afterMapperType  = reflect.TypeOf((*AfterMapper)(nil)).Elem()
+)
+
+// hookFunc is a lifecycle hook that receives the source and the destination structs, dst is addressable.
+type hookFunc func(src, dst reflect.Value) error
+
+// typePair is a pair of source and destination types that hooks are registered for.
+type typePair struct {
+	src reflect.Type
+	dst reflect.Type
+}
+
+// hooks contains the lifecycle hooks that are executed for mapping a source type to a destination type.
+type hooks struct {
+	before []hookFunc
+	after  []hookFunc
+}
+
+// hookKey returns the type pair that the hooks of S and D are registered for, sources are mapped by their
+// values, so a pointer S is registered for the type it points to.
+func hookKey[S, D any]() typePair {
+	src := reflect.TypeOf((*S)(nil)).Elem()
+	if src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+
+	return typePair{src: src, dst: reflect.TypeOf((*D)(nil)).Elem()}
+}
+
+// newTypedHook converts a hook that is registered for S and D into a hookFunc, if S is a pointer,
+// the hook receives a pointer to the source (or to a copy of it, if the source is not addressable).
+func newTypedHook[S, D any](fn func(src S, dst *D) error) hookFunc {
+	isPtr := reflect.TypeOf((*S)(nil)).Elem().Kind() == reflect.Ptr
+
+	return func(src, dst reflect.Value) error {
+		if isPtr {
+			if !src.CanAddr() {
+				addressable := reflect.New(src.Type()).Elem()
+				addressable.Set(src)
+				src = addressable
+			}
+
+			src = src.Addr()
+		}
+
+		return fn(src.Interface().(S), dst.Addr().Interface().(*D))
+	}
+}
+
+// hooks returns the hooks for mapping src to dst, the registered hooks are executed before the ones
+// that are discovered via BeforeMapper and AfterMapper.
+func (m *Mapper) hooks(src, dst reflect.Type) hooks {
+	registered := m.typeHooks[typePair{src: src, dst: dst}]
+
+	res := hooks{
+		before: append([]hookFunc(nil), registered.before...),
+		after:  append([]hookFunc(nil), registered.after...),
+	}
+
+	if reflect.PointerTo(dst).Implements(beforeMapperType) {
+		res.before = append(res.before, func(src, dst reflect.Value) error {
+			return dst.Addr().Interface().(BeforeMapper).BeforeMap(src.Interface())
+		})
+	}
+
+	if reflect.PointerTo(dst).Implements(afterMapperType) {
+		res.after = append(res.after, func(src, dst reflect.Value) error {
+			return dst.Addr().Interface().(AfterMapper).AfterMap(src.Interface())
+		})
+	}
+
+	return res
+}
+
+// runHooks executes the given hooks in order, and wraps their errors in a HookError.
+func runHooks(name string, fns []hookFunc, src, dst FieldValue) error {
+	for _, fn := range fns {
+		if err := fn(src.Value, dst.Value); err != nil {
+			return &HookError{
+				hook:    name,
+				srcType: src.Type(),
+				path:    dst.path,
+				err:     err,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package smapper
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type hookAddress struct {
+	City string
+}
+
+type hookAddressDTO struct {
+	City       string
+	Normalized bool
+}
+
+func (a *hookAddressDTO) AfterMap(src any) error {
+	a.City = strings.ToUpper(a.City)
+	a.Normalized = true
+
+	return nil
+}
+
+type hookUser struct {
+	Name      string
+	Addresses []hookAddress
+}
+
+type hookUserDTO struct {
+	Name      string
+	Addresses []hookAddressDTO
+	Source    string
+	MappedBy  string
+}
+
+func (u *hookUserDTO) BeforeMap(src any) error {
+	if _, ok := src.(hookUser); !ok {
+		return errors.New("unexpected source")
+	}
+
+	u.Source = "users"
+
+	return nil
+}
+
+func TestMap_Hooks(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+
+	mapper := New(
+		WithBeforeMap(func(src hookUser, dst *hookUserDTO) error {
+			calls = append(calls, "before")
+
+			// the fields are not mapped yet
+			assert.Empty(t, dst.Name)
+
+			return nil
+		}),
+		WithAfterMap(func(src hookUser, dst *hookUserDTO) error {
+			calls = append(calls, "after")
+			dst.MappedBy = "smapper"
+
+			return nil
+		}),
+	)
+
+	d := &hookUserDTO{}
+	assert.NoError(t, mapper.Map(hookUser{"alice", []hookAddress{{"tehran"}, {"paris"}}}, d))
+
+	assert.Equal(t, []string{"before", "after"}, calls)
+	assert.Equal(t, "users", d.Source)
+	assert.Equal(t, "smapper", d.MappedBy)
+
+	// the hooks of the nested structs are executed too
+	assert.Equal(t, []hookAddressDTO{{"TEHRAN", true}, {"PARIS", true}}, d.Addresses)
+}
+
+func TestMap_HookErrors(t *testing.T) {
+	t.Parallel()
+
+	errAudit := errors.New("audit failed")
+
+	mapper := New(WithAfterMap(func(src hookAddress, dst *hookAddressDTO) error {
+		return errAudit
+	}))
+
+	err := mapper.Map(hookUser{"alice", []hookAddress{{"tehran"}}}, &hookUserDTO{})
+
+	var hookErr *HookError
+	assert.ErrorAs(t, err, &hookErr)
+	assert.ErrorIs(t, err, errAudit)
+	assert.Contains(t, err.Error(), "hookUserDTO.Addresses[0]")
+}
+
+func TestMap_PointerSourceHooks(t *testing.T) {
+	t.Parallel()
+
+	var sources []*hookUser
+
+	mapper := New(WithAfterMap(func(src *hookUser, dst *hookUserDTO) error {
+		sources = append(sources, src)
+
+		return nil
+	}))
+
+	u := &hookUser{Name: "alice"}
+	assert.NoError(t, mapper.Map(u, &hookUserDTO{}))
+	assert.NoError(t, mapper.Map(hookUser{Name: "bob"}, &hookUserDTO{}))
+
+	// the hook receives the pointer that is mapped, or a pointer to a copy of the source
+	assert.Len(t, sources, 2)
+	assert.Same(t, u, sources[0])
+	assert.Equal(t, "bob", sources[1].Name)
+}
@@ -1,38 +1,178 @@
 package smapper
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 const (
+	defaultTagName = "smapper"
+
 	emptyTag    = ""
 	ignoreTag   = "-"
 	callbackTag = "callback:"
+	computeTag  = "compute:"
+	defaultTag  = "default="
+	diveTag     = "dive"
+	keysTag     = "keys"
+	endKeysTag  = "endkeys"
+	preTag      = "pre:"
+	postTag     = "post:"
+	messageTag  = "msg="
+
+	// dashTag is the field name of the fields that are named "-" by the fallback tags (e.g. json:"-,"),
+	// the smapper tags cannot name a field "-", and dashTag cannot be in them, since they're split by commas
+	dashTag = "-,"
 )
 
 type Mapper struct {
 	Config
-	callbacks  map[string]CallbackFunc
-	validators map[string]ValidatorFunc
+	callbacks  map[string]*Callback
+	computes   map[string]*Compute
+	validators map[string]validatorCompiler
+	enums      map[string][]any
+	// messages are the message templates of the validators' errors, keyed by the validators' names
+	messages map[string]string
+	// structValidators are executed after a struct of their type is populated
+	structValidators map[reflect.Type]func(reflect.Value) error
+	// naming is the strategy that is used to match fields, fields are matched by their exact name if it's nil
+	naming       NamingStrategy
+	structNaming map[reflect.Type]NamingStrategy
+	// typeHooks are the lifecycle hooks that are registered for pairs of source and destination types
+	typeHooks map[typePair]hooks
+	// tagName is the key of the tag that smapper reads, it's "smapper" if it's empty
+	tagName string
+	// tagFallbacks are the keys of the tags that are used for field names if tagName does not provide one
+	tagFallbacks []string
+	defaults     map[reflect.Type]map[string]any
+	// mappingRules are the rules of the mapping profiles, they replace the tags of the fields for their type pairs
+	mappingRules map[typePair]map[string]fieldRule
+	// registry contains the callbacks and validators that are shared with the other mappers
+	registry *Registry
+	plans    *planCache
+	// reverse is true for the mappers that are used by Reverse, they map destination types back to source types
+	reverse bool
+	// optionErr is the error of an invalid option (e.g. WithMappingProfile(nil))
+	optionErr error
+	// err is the configuration error of the options (e.g. an unknown callback in a mapping profile),
+	// it's returned whenever the mapper plans a mapping
+	err error
 }
 
 // New returns a new Mapper with the given options.
 func New(opts ...Option) *Mapper {
 	mapper := &Mapper{
-		callbacks:  make(map[string]CallbackFunc),
-		validators: make(map[string]ValidatorFunc),
+		callbacks:        make(map[string]*Callback),
+		computes:         make(map[string]*Compute),
+		validators:       make(map[string]validatorCompiler),
+		enums:            make(map[string][]any),
+		messages:         make(map[string]string),
+		structValidators: make(map[reflect.Type]func(reflect.Value) error),
+		structNaming:     make(map[reflect.Type]NamingStrategy),
+		typeHooks:        make(map[typePair]hooks),
+		defaults:         make(map[reflect.Type]map[string]any),
+		mappingRules:     make(map[typePair]map[string]fieldRule),
+		registry:         DefaultRegistry,
+		plans:            &planCache{},
 	}
 
 	for _, opt := range opts {
 		opt(mapper)
 	}
 
+	mapper.err = mapper.checkOptions()
+
 	return mapper
 }
 
+// With returns a new Mapper that has m's configuration and registrations plus the given options, m is not changed.
+// the new mapper shares m's compiled plans if the options only change the Config (e.g. WithStrict), because
+// the plans of different configs are cached separately, otherwise, its plans are compiled again.
+func (m *Mapper) With(opts ...Option) *Mapper {
+	res := m.clone()
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	if m.plans == nil || m.changesPlans(opts) {
+		res.plans = &planCache{}
+	}
+
+	// the options may register the missing callbacks and validators, or ignore them
+	res.err = res.checkOptions()
+
+	return res
+}
+
+// checkOptions returns the configuration error of the mapper's options, if there's any.
+func (m *Mapper) checkOptions() error {
+	if m.optionErr != nil {
+		return m.optionErr
+	}
+
+	return m.checkMappingRules()
+}
+
+// clone returns a copy of m whose maps and slices can be changed without changing m's.
+func (m *Mapper) clone() *Mapper {
+	res := *m
+
+	res.callbacks = maps.Clone(m.callbacks)
+	res.computes = maps.Clone(m.computes)
+	res.validators = maps.Clone(m.validators)
+	res.messages = maps.Clone(m.messages)
+	res.structValidators = maps.Clone(m.structValidators)
+	res.structNaming = maps.Clone(m.structNaming)
+	// slices are clipped, so appending to them never changes m's
+	res.tagFallbacks = slices.Clip(m.tagFallbacks)
+
+	res.enums = make(map[string][]any, len(m.enums))
+	for name, values := range m.enums {
+		res.enums[name] = slices.Clip(values)
+	}
+
+	res.typeHooks = make(map[typePair]hooks, len(m.typeHooks))
+	for pair, h := range m.typeHooks {
+		res.typeHooks[pair] = hooks{before: slices.Clip(h.before), after: slices.Clip(h.after)}
+	}
+
+	res.defaults = make(map[reflect.Type]map[string]any, len(m.defaults))
+	for t, defaults := range m.defaults {
+		res.defaults[t] = maps.Clone(defaults)
+	}
+
+	res.mappingRules = make(map[typePair]map[string]fieldRule, len(m.mappingRules))
+	for pair, rules := range m.mappingRules {
+		res.mappingRules[pair] = maps.Clone(rules)
+	}
+
+	return &res
+}
+
+// changesPlans checks if any of the options changes something other than the Config, it applies the options
+// to an empty mapper, and checks if anything other than its Config is set.
+func (m *Mapper) changesPlans(opts []Option) bool {
+	probe := New()
+	// setting any registry (even m's or nil) replaces this one, so it's detected
+	sentinel := &Registry{}
+	probe.registry = sentinel
+
+	for _, opt := range opts {
+		opt(probe)
+	}
+
+	return len(probe.callbacks) > 0 || len(probe.computes) > 0 || len(probe.validators) > 0 ||
+		len(probe.enums) > 0 || len(probe.messages) > 0 || len(probe.structValidators) > 0 ||
+		len(probe.structNaming) > 0 || len(probe.typeHooks) > 0 || len(probe.defaults) > 0 || len(probe.mappingRules) > 0 ||
+		probe.naming != nil || probe.tagName != "" || probe.tagFallbacks != nil || probe.registry != sentinel
+}
+
 // Map takes a struct and converts it into another struct, output type must be a pointer to a struct.
 func (m *Mapper) Map(input, output any) error {
 	err := validateInputTypes(reflect.TypeOf(input), reflect.TypeOf(output))
@@ -46,7 +186,7 @@ func (m *Mapper) Map(input, output any) error {
 	}
 	dstVal := reflect.ValueOf(output).Elem()
 
-	err = m.mapTypes(FieldValue{Value: srcVal}, FieldValue{Value: dstVal})
+	err = m.mapTypes(FieldValue{Value: srcVal}, FieldValue{Value: dstVal, path: dstVal.Type().Name()})
 	if err != nil {
 		return err
 	}
@@ -55,76 +195,181 @@ func (m *Mapper) Map(input, output any) error {
 }
 
 func (m *Mapper) mapTypes(src, dst FieldValue) error {
-	for i := 0; i < dst.NumField(); i++ {
-		dstField := dst.Field(i)
+	plan, err := m.plan(src.Type(), dst.Type())
+	if err != nil {
+		return err
+	}
+
+	// the strict checks only need the plan, so they're done before anything is written into dst
+	var unmapped, unused []string
+	if m.Strict {
+		unmapped = plan.unmapped
+	}
+
+	if m.StrictSource {
+		unused = plan.unused
+	}
 
-		// ignores the unexported field
-		if !dst.Field(i).CanSet() {
+	if len(unmapped) > 0 || len(unused) > 0 {
+		return &UnmappedFieldsError{
+			srcType:      src.Type(),
+			dstType:      dst.Type(),
+			unmapped:     unmapped,
+			unusedSource: unused,
+		}
+	}
+
+	if err := runHooks("BeforeMap", plan.hooks.before, src, dst); err != nil {
+		return err
+	}
+
+	for _, f := range plan.fields {
+		// computed fields are populated after the other fields
+		if f.ignored || f.opts.compute != nil {
 			continue
 		}
 
-		field := dst.Type().Field(i)
-		fieldName := field.Name
+		dstField := f.target(dst)
 
-		// get the field name, callback function, and validator functions that
-		// need to be executed before setting the value
-		dstTags, err := m.parseTagValues(getTagValues(dst, field.Name))
-		if err != nil {
-			return err
+		value, found := f.sourceValue(src)
+
+		// the default value is already converted to the destination type, so it's set as is
+		isDefault := f.defaultValue.IsValid() && (!found || value.IsZero())
+		if isDefault {
+			value = f.newDefault()
+		} else if !found {
+			continue
 		}
 
-		if dstTags.field != emptyTag {
-			if dstTags.field == ignoreTag {
-				continue
-			}
+		// execute parsed pre-conversion validators
+		field := NewFieldValue(value, src.Type(), f.name)
+		field.path = dst.path + "." + f.name
 
-			// use the provided field name in the field tag instead of the actual field name
-			fieldName = dstTags.field
+		err := validate(field, src.Value, f.opts.validators, f.opts.dive, false)
+		if err != nil {
+			return err
 		}
 
-		// search for the field in the input type, and ignore it if it's zero value, or it's unexported
-		if value := src.FieldByName(fieldName); value.IsValid() && value.CanInterface() {
-			// execute parsed validators
-			for _, v := range dstTags.validators {
-				if !v.fn(value, v.param) {
-					return &ValidationError{
-						value:         NewFieldValue(value, src.Type(), field.Name),
-						validatorName: v.name,
-					}
+		// the default value is assigned as is, without the callbacks and the conversion
+		assigned := true
+
+		if !isDefault {
+			// execute parsed callbacks, each of them receives the previous one's output
+			for i, c := range f.opts.callbacks {
+				out, err := c.fn(value.Type(), dstField.Type(), value.Interface(), c.param)
+				if err == nil && out == nil {
+					err = errors.New("callback returned nil")
 				}
-			}
 
-			if dstTags.callback != nil {
-				// execute parsed callback
-				m, err := dstTags.callback(value.Type(), dstField.Type(), value.Interface())
 				if err != nil {
 					return &CallbackError{
-						value: NewFieldValue(value, src.Type(), field.Name),
-						msg:   err.Error(),
+						value:        NewFieldValue(value, src.Type(), f.name),
+						msg:          err.Error(),
+						callbackName: c.name,
+						step:         i + 1,
+						err:          err,
 					}
 				}
 
-				value = reflect.ValueOf(m)
+				value = reflect.ValueOf(out)
 			}
 
 			if value.Type() != dstField.Type() {
 				// try to convert the source type to the destination type or return an error
 				// if the conversion is impossible.
-				v, err := m.convert(NewFieldValue(value, src.Type(), fieldName), dst.From(dstField))
+				v, err := m.convert(NewFieldValue(value, src.Type(), f.srcName), dst.field(newTarget(dstField), f.name))
 				if err != nil {
 					return err
 				}
 
-				value = v.Value
+				value, assigned = v.Value, false
 			}
+		}
 
-			dstField.Set(value)
+		// execute parsed post-conversion validators, the value is set only if they pass, so a failed
+		// validation doesn't leave the field half written
+		if err := m.validateField(f, field.From(value), src.Value, assigned); err != nil {
+			return err
 		}
+
+		dstField.Set(value)
+	}
+
+	if err := m.computeFields(plan, src, dst); err != nil {
+		return err
+	}
+
+	if err := runHooks("AfterMap", plan.hooks.after, src, dst); err != nil {
+		return err
+	}
+
+	// the struct is fully populated here, including its nested structs
+	return m.validateStruct(dst)
+}
+
+// computeFields populates the computed fields of dst in order, using their compute functions.
+func (m *Mapper) computeFields(plan *structPlan, src, dst FieldValue) error {
+	for _, f := range plan.fields {
+		if f.ignored || f.opts.compute == nil {
+			continue
+		}
+
+		dstField := dst.Field(f.index)
+
+		out, err := f.opts.compute.Func(src.Value, dst.Value)
+		if err == nil && out == nil {
+			err = errors.New("compute returned nil")
+		}
+
+		if err != nil {
+			return &CallbackError{
+				value:        NewFieldValue(dstField, dst.Type(), f.name),
+				msg:          err.Error(),
+				callbackName: f.opts.compute.Name,
+				err:          err,
+			}
+		}
+
+		value := reflect.ValueOf(out)
+
+		field := NewFieldValue(value, src.Type(), f.name)
+		field.path = dst.path + "." + f.name
+
+		if err := validate(field, src.Value, f.opts.validators, f.opts.dive, false); err != nil {
+			return err
+		}
+
+		if value.Type() != dstField.Type() {
+			v, err := m.convert(field, dst.field(newTarget(dstField), f.name))
+			if err != nil {
+				return err
+			}
+
+			value = v.Value
+		}
+
+		if err := m.validateField(f, field.From(value), src.Value, true); err != nil {
+			return err
+		}
+
+		dstField.Set(value)
 	}
 
 	return nil
 }
 
+// newTarget returns a new value of the field's type that a value is converted into, so the field is set only
+// after the converted value is validated. structs are mapped field by field, so the target of a struct is a copy
+// of the field, and the fields that are not mapped keep their values.
+func newTarget(field reflect.Value) reflect.Value {
+	res := reflect.New(field.Type()).Elem()
+	if field.Kind() == reflect.Struct {
+		res.Set(field)
+	}
+
+	return res
+}
+
 // convert converts src type to dst type, returns error if the conversion is impossible. (e.g. map to slice).
 func (m *Mapper) convert(src, dst FieldValue) (FieldValue, error) {
 	var err error
@@ -141,20 +386,20 @@ func (m *Mapper) convert(src, dst FieldValue) (FieldValue, error) {
 				msg:   fmt.Sprintf("cannot auto convert %s to %s", src.Type(), dst.Type()),
 			}
 		}
-		dst = FieldValue{Value: reflect.MakeMap(dst.Type()), ParentType: dst.ParentType, FieldName: dst.FieldName}
+		dst = dst.From(reflect.MakeMap(dst.Type()))
 
 		dstKey := dst.Type().Key()
 		dstVal := dst.Type().Elem()
 
 		iter := src.MapRange()
 		for iter.Next() {
-			key := FieldValue{Value: iter.Key()}
-			val := FieldValue{Value: iter.Value()}
+			key := src.From(iter.Key())
+			val := src.From(iter.Value())
 
 			if key.Type() != dstKey {
 				zeroKey := reflect.New(dstKey).Elem()
 
-				key, err = m.convert(key, FieldValue{Value: zeroKey})
+				key, err = m.convert(key, dst.From(zeroKey))
 				if err != nil {
 					return FieldValue{}, err
 				}
@@ -163,7 +408,7 @@ func (m *Mapper) convert(src, dst FieldValue) (FieldValue, error) {
 			if val.Type() != dstVal {
 				zeroVal := reflect.New(dstVal).Elem()
 
-				val, err = m.convert(val, FieldValue{Value: zeroVal})
+				val, err = m.convert(val, dst.element(zeroVal, iter.Key()))
 				if err != nil {
 					return FieldValue{}, err
 				}
@@ -185,7 +430,7 @@ func (m *Mapper) convert(src, dst FieldValue) (FieldValue, error) {
 		dst.SetLen(src.Len())
 
 		for i := 0; i < src.Len(); i++ {
-			_, err := m.convert(src.From(src.Index(i)), dst.From(dst.Index(i)))
+			_, err := m.convert(src.From(src.Index(i)), dst.element(dst.Index(i), i))
 			if err != nil {
 				return dst, err
 			}
@@ -211,6 +456,19 @@ func (m *Mapper) convert(src, dst FieldValue) (FieldValue, error) {
 			return dst, err
 		}
 	case reflect.Struct:
+		// pointers to structs are mapped into the structs, and nil pointers leave them untouched
+		src.Value = reflect.Indirect(src.Value)
+		if !src.IsValid() {
+			return dst, nil
+		}
+
+		if src.Kind() != reflect.Struct {
+			return dst, &FieldError{
+				value: src,
+				msg:   fmt.Sprintf("cannot auto convert %s to %s", src.Type(), dst.Type()),
+			}
+		}
+
 		err := m.mapTypes(src, dst)
 		if err != nil {
 			return src, err
@@ -337,11 +595,11 @@ func (m *Mapper) convertFloats(src, dst FieldValue) error {
 func (m *Mapper) convertStrings(src, dst FieldValue) error {
 	src.Value = reflect.Indirect(src.Value)
 
-	if src.Kind() != reflect.String && !m.AutoStringToNumberConversion {
+	if src.Kind() != reflect.String && !m.AutoNumberToStringConversion {
 		return &FieldError{
 			value: src,
 			msg: fmt.Sprintf(
-				"want %s, got %s (if you want to auto convert numbers to strings, set AutoStringToNumberConversion to true",
+				"want %s, got %s (if you want to auto convert numbers to strings, set AutoNumberToStringConversion to true",
 				src.Type(), dst.Type()),
 		}
 	}
@@ -395,33 +653,125 @@ func MapToWith[T any](mapper *Mapper, input any) (*T, error) {
 	return output, mapper.Map(input, output)
 }
 
+// callback is a callback in a field's tag.
+type callback struct {
+	name  string
+	param string
+	fn    ParamCallbackFunc
+	// in and out are the types of a typed callback's input and output, they're nil if the types are unknown
+	in  reflect.Type
+	out reflect.Type
+	// inverse is the callback that reverses this one, it's nil if the callback cannot be reversed
+	inverse *Callback
+}
+
 type fieldOptions struct {
-	field      string
-	callback   CallbackFunc
+	field string
+	// callbacks are executed in order, each of them receives the previous one's output
+	callbacks []callback
+	// compute computes the field's value using the whole source struct, instead of mapping a source field
+	compute    *Compute
 	validators []validator
+	// dive contains the validators of the elements, if there's a dive tag
+	dive *dive
+	// defaultValue is the literal that is used when the source field is missing or zero
+	defaultValue *string
+	// message is the message of the field's validation errors, it's used instead of the validators' messages
+	message string
+}
+
+// callbackNames returns the names (and params) of the callbacks separated by commas (e.g. trim,truncate=64).
+func (o fieldOptions) callbackNames() string {
+	names := make([]string, 0, len(o.callbacks))
+	for _, c := range o.callbacks {
+		if c.param == "" {
+			names = append(names, c.name)
+		} else {
+			names = append(names, c.name+"="+c.param)
+		}
+	}
+
+	return strings.Join(names, ",")
 }
 
 func (m *Mapper) parseTagValues(tags []string) (fieldOptions, error) {
-	var res fieldOptions
+	var (
+		res fieldOptions
+		// current is the dive that the next validators belong to, it's nil before the first dive
+		current *dive
+		// target is where the next validators are added
+		target = &res.validators
+	)
 
 	for i, tag := range tags {
 		if i == 0 {
-			res.field = toPascalCase(tag)
+			res.field = tag
 
 			continue
 		}
 
-		if funcName, found := strings.CutPrefix(tag, callbackTag); found {
-			fn, found := m.callbacks[funcName]
+		switch tag {
+		case diveTag:
+			d := &dive{}
+			if current == nil {
+				res.dive = d
+			} else {
+				current.dive = d
+			}
+
+			current, target = d, &d.validators
+			continue
+		case keysTag:
+			if current == nil || len(current.validators) > 0 || current.keys != nil {
+				return fieldOptions{}, &Error{msg: "keys must be used right after dive"}
+			}
+
+			current.keys, target = []validator{}, &current.keys
+			continue
+		case endKeysTag:
+			if current == nil || target != &current.keys {
+				return fieldOptions{}, &Error{msg: "endkeys must be used after keys"}
+			}
+
+			target = &current.validators
+			continue
+		}
+
+		if literal, found := strings.CutPrefix(tag, defaultTag); found {
+			res.defaultValue = &literal
+			continue
+		}
+
+		if msg, found := strings.CutPrefix(tag, messageTag); found {
+			res.message = unquote(msg)
+			continue
+		}
+
+		if name, found := strings.CutPrefix(tag, computeTag); found {
+			c, found := m.computes[name]
 			if !found {
 				if m.IgnoreMissingCallbacks {
 					continue
 				}
 
-				return fieldOptions{}, &Error{msg: fmt.Sprintf("cannot find callback %s", funcName)}
+				return fieldOptions{}, &Error{msg: fmt.Sprintf("cannot find compute %s", name)}
+			}
+
+			res.compute = c
+			continue
+		}
+
+		if value, found := strings.CutPrefix(tag, callbackTag); found {
+			c, found, err := m.parseCallback(value)
+			if !found && m.IgnoreMissingCallbacks {
+				continue
 			}
 
-			res.callback = fn
+			if err != nil {
+				return fieldOptions{}, err
+			}
+
+			res.callbacks = append(res.callbacks, c)
 			continue
 		}
 
@@ -434,26 +784,78 @@ func (m *Mapper) parseTagValues(tags []string) (fieldOptions, error) {
 			return fieldOptions{}, err
 		}
 
-		res.validators = append(res.validators, v)
+		*target = append(*target, v)
+	}
+
+	if current != nil && target == &current.keys {
+		return fieldOptions{}, &Error{msg: "keys must be closed using endkeys"}
 	}
 
+	m.setMessages(res.validators, res.dive, res.message)
+
 	return res, nil
 }
 
+// parseCallback parses a callback's tag value, which is the callback's name and its optional param
+// (e.g. truncate=64), params can contain '=', and commas if they're quoted (e.g. format='Jan 2, 2006').
+// it returns false if the callback is not registered.
+func (m *Mapper) parseCallback(value string) (callback, bool, error) {
+	name, param, hasParam := strings.Cut(value, "=")
+
+	c, found := m.callbacks[name]
+	if !found && m.registry != nil {
+		c, found = m.registry.callback(name)
+	}
+
+	if !found {
+		return callback{}, false, &Error{msg: fmt.Sprintf("cannot find callback %s", name)}
+	}
+
+	fn, acceptsParam := c.paramFunc()
+	if hasParam && !acceptsParam {
+		return callback{}, true, &Error{msg: fmt.Sprintf("callback %s does not accept params", name)}
+	}
+
+	return callback{name: name, param: unquote(param), fn: fn, in: c.in, out: c.out, inverse: c.Inverse}, true, nil
+}
+
 func (m *Mapper) parseValidator(tag string) (validator, error) {
 	v := parseValidatorTag(tag)
 
-	v.fn = defaultValidators[v.name]
+	v.compile = defaultValidators[v.name]
+	if fn, found := crossFieldValidators[v.name]; found {
+		v.compile = fn
+	}
 
-	if m.validators != nil {
-		if fn, found := m.validators[v.name]; found {
-			if v.fn == nil || (v.fn != nil && m.OverrideDefaultValidators) {
-				v.fn = fn
-			}
+	// enum validators depend on the enums that are registered in the mapper
+	if v.name == enumValidator {
+		fn, err := m.enumValidator(v.param)
+		if err != nil {
+			return validator{}, err
 		}
+
+		v.compile = fn
 	}
 
-	if v.fn == nil {
+	// the string validators can be replaced by custom validators, regardless of OverrideDefaultValidators
+	builtIn := v.compile != nil
+	if !builtIn {
+		v.compile = stringValidators[v.name]
+	}
+
+	// validators that are registered in the mapper take precedence over the ones in its registry
+	fn, found := m.validators[v.name]
+	if !found && m.registry != nil {
+		if custom, inRegistry := m.registry.validator(v.name); inRegistry {
+			fn, found = custom.compiler(), true
+		}
+	}
+
+	if found && (!builtIn || m.OverrideDefaultValidators) {
+		v.compile = fn
+	}
+
+	if v.compile == nil {
 		return validator{}, &Error{msg: fmt.Sprintf("cannot find validator %s", v.name)}
 	}
 
@@ -463,19 +865,185 @@ func (m *Mapper) parseValidator(tag string) (validator, error) {
 func parseValidatorTag(tag string) validator {
 	var v validator
 
-	p := strings.Split(tag, "=")
-	v.name = p[0]
-	if len(p) > 1 {
-		v.param = p[1]
+	// the phase can be set explicitly (e.g. pre:gte=3), otherwise, the validator's default phase is used
+	if rest, found := strings.CutPrefix(tag, preTag); found {
+		tag, v.phase = rest, phasePre
+	} else if rest, found := strings.CutPrefix(tag, postTag); found {
+		tag, v.phase = rest, phasePost
 	}
 
+	// params can contain '=' (e.g. regexp=^a=b$)
+	v.name, v.param, _ = strings.Cut(tag, "=")
+	v.param = unquote(v.param)
+
 	return v
 }
 
-func getTagValues(v FieldValue, name string) []string {
-	f, _ := v.Type().FieldByName(name)
+// splitTag splits the tag's values by commas, commas between single quotes are not considered as
+// separators (e.g. "regexp='^[a-z]{1,3}$',required" is split into "regexp='^[a-z]{1,3}$'" and "required").
+func splitTag(tag string) []string {
+	var (
+		res    []string
+		quoted bool
+		start  int
+	)
+
+	for i := 0; i < len(tag); i++ {
+		switch tag[i] {
+		case '\'':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				res = append(res, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(res, tag[start:])
+}
+
+// unquote removes the single quotes around s, if there are any.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' && !strings.ContainsRune(s[1:len(s)-1], '\'') {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
+
+// splitParams splits a multi-value param by spaces, spaces between single quotes are not considered as
+// separators (e.g. "'light blue' red" is split into "light blue" and "red").
+func splitParams(param string) []string {
+	var (
+		res    []string
+		quoted bool
+		sb     strings.Builder
+	)
+
+	flush := func() {
+		if sb.Len() > 0 {
+			res = append(res, sb.String())
+			sb.Reset()
+		}
+	}
+
+	for _, r := range param {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			flush()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	flush()
+
+	return res
+}
+
+// getTagValues returns the values of the field's tag, the profile's tag (e.g. `smapper.db:"user_name"`) replaces
+// the field's tag if it's present. if the tag does not provide a field name, the name is taken from the first
+// fallback tag that has one (e.g. `json:"user_id,omitempty"`).
+func (m *Mapper) getTagValues(f reflect.StructField) []string {
+	key := m.tagName
+	if key == "" {
+		key = defaultTagName
+	}
+
+	tag := f.Tag.Get(key)
+	if m.Profile != "" {
+		if profileTag, found := f.Tag.Lookup(key + "." + m.Profile); found {
+			tag = profileTag
+		}
+	}
+
+	values := splitTag(tag)
+	if values[0] != emptyTag {
+		return values
+	}
+
+	values[0] = m.fallbackName(f)
+
+	return values
+}
+
+// fallbackName returns the field name of the first fallback tag of f that has one, it returns ignoreTag if
+// the tag ignores the field, and emptyTag if none of the fallback tags provides a name.
+func (m *Mapper) fallbackName(f reflect.StructField) string {
+	for _, key := range m.tagFallbacks {
+		tag, found := f.Tag.Lookup(key)
+		if !found {
+			continue
+		}
+
+		// modifiers like omitempty are not needed here
+		name, _, _ := strings.Cut(tag, ",")
+		if tag != ignoreTag && name == ignoreTag {
+			// just like encoding/json, "-," names the field "-" instead of ignoring it
+			name = dashTag
+		}
+
+		if tag == ignoreTag || name != emptyTag {
+			return name
+		}
+	}
+
+	return emptyTag
+}
+
+// findFallbackField searches for the exported field of t whose fallback tags name it the given name (e.g.
+// `json:"user_id"`), so the fields of two structs match if their tags agree, even if their Go names don't.
+func (m *Mapper) findFallbackField(t reflect.Type, name string, naming NamingStrategy) (reflect.StructField, bool) {
+	if len(m.tagFallbacks) == 0 || strings.Contains(name, ".") {
+		return reflect.StructField{}, false
+	}
+
+	if naming == nil {
+		naming = func(s string) string { return s }
+	}
+
+	want := naming(name)
+
+	var (
+		res   reflect.StructField
+		found bool
+	)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (found && len(f.Index) >= len(res.Index)) {
+			continue
+		}
+
+		tagName := m.fallbackName(f)
+		switch tagName {
+		case emptyTag, ignoreTag:
+			continue
+		case dashTag:
+			tagName = ignoreTag
+		}
+
+		if naming(tagName) == want {
+			res, found = f, true
+		}
+	}
+
+	return res, found
+}
+
+// unusedFields returns the exported fields of t that are not marked as used.
+func unusedFields(t reflect.Type, used map[int]bool) []string {
+	var res []string
+
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() || used[i] {
+			continue
+		}
+
+		res = append(res, t.Field(i).Name)
+	}
 
-	return strings.Split(f.Tag.Get("smapper"), ",")
+	return res
 }
 
 func validateInputTypes(src, dst reflect.Type) error {
@@ -131,3 +131,32 @@ func ExampleMapTo() {
 	fmt.Println(user.ID)   // 42
 	fmt.Println(user.Name) // alir32a
 }
+
+func ExampleExplain() {
+	type Person struct {
+		ID       uint
+		Username string
+		Password string
+	}
+
+	type User struct {
+		ID    int64
+		Name  string `smapper:"username,required"`
+		Email string
+	}
+
+	plan, err := smapper.Explain[Person, User](smapper.New())
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(plan)
+	// Output:
+	// smapper_test.Person -> smapper_test.User
+	// FIELD  SOURCE    CONVERSION  CALLBACK  VALIDATORS
+	// ID     ID        numeric     -         -
+	// Name   Username  direct      -         required
+	// Email  -         none        -         -
+	// unmapped: Email
+	// unused source: Password
+}
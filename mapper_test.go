@@ -2,11 +2,14 @@ package smapper
 
 import (
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"math"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 type Simple struct {
@@ -198,6 +201,57 @@ func TestMap_DifferentTypes(t *testing.T) {
 	}
 }
 
+func TestMap_PointerToStruct(t *testing.T) {
+	t.Parallel()
+
+	type inner struct {
+		Name string
+	}
+
+	type innerDst struct {
+		Name string `smapper:",required"`
+	}
+
+	type src struct {
+		Inner  *inner
+		Inners []*inner
+		Count  int
+	}
+
+	type dst struct {
+		Inner  innerDst
+		Inners []innerDst
+		Count  innerDst
+	}
+
+	mapper := New()
+
+	// the plan and the mapping agree on the pointers to structs
+	plan, err := Explain[src, dst](mapper)
+	assert.NoError(t, err)
+	assert.Equal(t, ConversionStruct, plan.Fields[0].Conversion)
+	assert.NotNil(t, plan.Fields[0].Nested)
+	assert.Equal(t, ConversionUnsupported, plan.Fields[2].Conversion)
+
+	type pointersDst struct {
+		Inner  innerDst
+		Inners []innerDst
+	}
+
+	d := pointersDst{}
+	assert.NoError(t, mapper.Map(src{Inner: &inner{"alice"}, Inners: []*inner{{"bob"}}}, &d))
+	assert.Equal(t, pointersDst{Inner: innerDst{"alice"}, Inners: []innerDst{{"bob"}}}, d)
+
+	// nil pointers leave the structs untouched
+	d = pointersDst{Inner: innerDst{"carol"}}
+	assert.NoError(t, mapper.Map(src{}, &d))
+	assert.Equal(t, pointersDst{Inner: innerDst{"carol"}}, d)
+
+	// non-struct sources cannot be mapped into structs
+	var fieldErr *FieldError
+	assert.ErrorAs(t, mapper.Map(src{Count: 1}, &dst{}), &fieldErr)
+}
+
 func TestMap_Callback(t *testing.T) {
 	t.Parallel()
 
@@ -271,6 +325,22 @@ func TestMap_OverrideDefaultValidators(t *testing.T) {
 	assert.Error(t, with.Map(src, dst),
 		"should have error because we overriden eq func to do the opposite (OverrideDefaultValidators=true)")
 	assert.Nil(t, without.Map(src, dst), "should be nil, OverrideDefaultValidators=false")
+
+	// custom validators take precedence over the string validators, even if OverrideDefaultValidators=false
+	type contact struct {
+		Email string `smapper:",email"`
+	}
+
+	registry := NewRegistry()
+	registry.RegisterValidator(NewValidator("email", func(v reflect.Value, param string) bool {
+		return strings.HasSuffix(v.String(), "@example.com")
+	}))
+
+	custom := New(WithRegistry(registry))
+
+	assert.NoError(t, New().Map(contact{Email: "alice@test.org"}, &contact{}))
+	assert.Error(t, custom.Map(contact{Email: "alice@test.org"}, &contact{}))
+	assert.NoError(t, custom.Map(contact{Email: "alice@example.com"}, &contact{}))
 }
 
 func TestMap_IgnoreMissingValidators(t *testing.T) {
@@ -344,6 +414,14 @@ func TestMap_AutoNumberToStringConversion(t *testing.T) {
 	assert.Error(t, validMapper.Map(invalidSrc, &d))
 	assert.NoError(t, validMapper.Map(validSrc, &d))
 
+	// each direction has its own option
+	type strDst struct {
+		StrInt string `smapper:"int"`
+	}
+
+	assert.Error(t, New(WithAutoStringToNumberConversion()).Map(validSrc, &strDst{}))
+	assert.NoError(t, New(WithAutoNumberToStringConversion()).Map(validSrc, &strDst{}))
+
 	assert.Equal(t, strconv.Itoa(validSrc.Int), d.StrInt)
 
 	f, _ := strconv.ParseFloat(validSrc.String, 64)
@@ -366,3 +444,547 @@ func TestMapAndReturn(t *testing.T) {
 	assert.Equal(t, simple.String, anotherSimple.String)
 	assert.NotEqual(t, simple.float, anotherSimple.Float)
 }
+
+func TestMap_Strict(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		ID       int
+		Name     string
+		Email    string
+		Password string
+	}
+
+	type dst struct {
+		ID       int
+		Username string `smapper:"name"`
+		Phone    string
+		Address  string
+		Internal string `smapper:"-"`
+	}
+
+	s := src{ID: 1, Name: "admin", Email: "admin@example.com", Password: "secret"}
+
+	assert.NoError(t, New().Map(s, &dst{}), "should not have error because Strict = false")
+
+	var unmappedErr *UnmappedFieldsError
+
+	d := dst{}
+	err := New(WithStrict()).Map(s, &d)
+	assert.ErrorAs(t, err, &unmappedErr)
+	assert.Equal(t, []string{"Phone", "Address"}, unmappedErr.UnmappedFields())
+	assert.Empty(t, unmappedErr.UnusedSourceFields(), "should be empty because StrictSource = false")
+	assert.Equal(t, dst{}, d, "should not modify dst because the strict check failed")
+
+	err = New(WithStrictSource()).Map(s, &dst{})
+	assert.ErrorAs(t, err, &unmappedErr)
+	assert.Empty(t, unmappedErr.UnmappedFields(), "should be empty because Strict = false")
+	assert.Equal(t, []string{"Email", "Password"}, unmappedErr.UnusedSourceFields())
+
+	err = New(WithStrict(), WithStrictSource()).Map(s, &dst{})
+	assert.ErrorAs(t, err, &unmappedErr)
+	assert.Equal(t, []string{"Phone", "Address"}, unmappedErr.UnmappedFields())
+	assert.Equal(t, []string{"Email", "Password"}, unmappedErr.UnusedSourceFields())
+
+	// promoted fields mark their embedded struct as used
+	type embedded struct {
+		Simple
+	}
+
+	assert.NoError(t, New(WithStrict(), WithStrictSource()).Map(embedded{}, &Simple{}))
+}
+
+func TestMap_TagFallbacks(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		UserID   int
+		Username string
+		Password string
+	}
+
+	type dst struct {
+		ID       int    `json:"user_id,omitempty" db:"UserID"`
+		Name     string `smapper:"username" json:"name"`
+		Password string `json:"-"`
+		Email    string `json:",omitempty" db:"username"`
+	}
+
+	s := src{UserID: 42, Username: "admin", Password: "secret"}
+
+	d := dst{}
+	assert.NoError(t, New().Map(s, &d))
+	assert.Equal(t, dst{Name: "admin", Password: "secret"}, d, "should not use json and db tags without fallbacks")
+
+	d = dst{}
+	assert.NoError(t, New(WithTagFallbacks("json", "db"), WithNamingStrategy(SnakeCase)).Map(s, &d))
+	assert.Equal(t, dst{ID: 42, Name: "admin", Email: "admin"}, d)
+
+	// json:"-," names the field "-" (just like encoding/json), so it's not ignored, and it has no source field
+	type dashDst struct {
+		Password string `json:"-,"`
+	}
+
+	type ignoredDst struct {
+		Password string `json:"-"`
+	}
+
+	var unmappedErr *UnmappedFieldsError
+	strict := New(WithTagFallbacks("json"), WithStrict())
+	assert.NoError(t, New(WithTagFallbacks("json")).Map(s, &dashDst{}))
+	assert.ErrorAs(t, strict.Map(s, &dashDst{}), &unmappedErr)
+	assert.NoError(t, strict.Map(s, &ignoredDst{}))
+
+	// the source fields are matched by their fallback tags too, so the fields match if only their tags agree
+	type taggedSrc struct {
+		UID   int    `json:"user_id"`
+		Login string `json:"-" db:"login"`
+		Dash  string `json:"-,"`
+	}
+
+	type taggedDst struct {
+		UserID   int    `json:"user_id"`
+		Username string `db:"LOGIN"`
+		Password string `json:"-,"`
+	}
+
+	td := taggedDst{}
+	assert.NoError(t, New(WithTagFallbacks("json")).Map(taggedSrc{42, "admin", "secret"}, &td))
+	assert.Equal(t, taggedDst{UserID: 42, Password: "secret"}, td, "json:\"-\" source fields are not matched")
+
+	td = taggedDst{}
+	assert.NoError(t, New(WithTagFallbacks("db", "json"), WithNamingStrategy(SnakeCase)).Map(taggedSrc{42, "admin", ""}, &td))
+	assert.Equal(t, taggedDst{UserID: 42, Username: "admin"}, td)
+
+	type mapDst struct {
+		ID   int    `map:"UserID"`
+		Name string `smapper:"-" map:"username"`
+	}
+
+	md := mapDst{}
+	assert.NoError(t, New(WithTagName("map")).Map(s, &md))
+	assert.Equal(t, mapDst{ID: 42, Name: "admin"}, md)
+}
+
+func TestMap_Profiles(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		Username string
+		Email    string
+	}
+
+	type row struct {
+		UserName string
+		Mail     string
+		Secret   string
+	}
+
+	type user struct {
+		Name   string `smapper:"username" smapper.db:"user_name,required"`
+		Email  string `smapper.db:"mail"`
+		Secret string `smapper.db:"-"`
+	}
+
+	u := user{}
+	assert.NoError(t, New().Map(request{"alice", "alice@example.com"}, &u))
+	assert.Equal(t, user{Name: "alice", Email: "alice@example.com"}, u)
+
+	mapper := New(WithProfile("db"), WithNamingStrategy(SnakeCase))
+
+	u = user{}
+	assert.NoError(t, mapper.Map(row{"bob", "bob@example.com", "secret"}, &u))
+	assert.Equal(t, user{Name: "bob", Email: "bob@example.com"}, u)
+
+	// the profile's tag replaces the smapper tag, including its validators
+	assert.Error(t, mapper.Map(row{}, &user{}))
+	assert.NoError(t, New().Map(request{}, &user{}))
+
+	// the derived mapper shares the compiled plans with its base, but it doesn't use the plans of other profiles
+	base := New()
+	assert.NoError(t, base.Map(request{Username: "carol"}, &user{}))
+
+	u = user{}
+	assert.NoError(t, base.With(WithProfile("db")).Map(request{Username: "carol"}, &u))
+	assert.Empty(t, u.Name)
+}
+
+func TestMap_Defaults(t *testing.T) {
+	t.Parallel()
+
+	type status string
+
+	type src struct {
+		Name  string
+		Limit int
+	}
+
+	type dst struct {
+		Name    string  `smapper:",default=guest"`
+		Status  status  `smapper:",default=active"`
+		Limit   uint    `smapper:",default=10,gte=5"`
+		Ratio   float64 `smapper:",default=0.5"`
+		Enabled *bool   `smapper:",default=true"`
+		Retries int
+	}
+
+	mapper := New(WithStrict(), WithDefault[dst]("Retries", 3))
+
+	d := dst{}
+	assert.NoError(t, mapper.Map(src{}, &d))
+	assert.Equal(t, "guest", d.Name)
+	assert.Equal(t, status("active"), d.Status)
+	assert.EqualValues(t, 10, d.Limit)
+	assert.Equal(t, 0.5, d.Ratio)
+	assert.True(t, *d.Enabled)
+	assert.Equal(t, 3, d.Retries)
+
+	another := dst{}
+	assert.NoError(t, mapper.Map(src{}, &another))
+	assert.NotSame(t, d.Enabled, another.Enabled, "default pointers should not be shared")
+
+	d = dst{}
+	assert.NoError(t, mapper.Map(src{Name: "admin", Limit: 7}, &d))
+	assert.Equal(t, "admin", d.Name)
+	assert.EqualValues(t, 7, d.Limit)
+
+	// validators are executed after the default value is set
+	type invalid struct {
+		Limit int `smapper:",default=1,gte=5"`
+	}
+	assert.Error(t, New().Map(src{}, &invalid{}), "should have error because the default value is less than 5")
+
+	type notConvertible struct {
+		Limit int `smapper:",default=ten"`
+	}
+	assert.Error(t, New().Map(src{}, &notConvertible{}), "should have error because 'ten' is not a number")
+
+	// the fields of WithDefault must exist and be exported
+	type withUnexported struct {
+		Status string
+		secret string
+	}
+
+	var configErr *ConfigError
+	assert.ErrorAs(t, New(WithDefault[withUnexported]("Stauts", "x")).Map(src{}, &withUnexported{}), &configErr)
+	assert.EqualError(t, configErr,
+		"smapper: invalid configuration for withUnexported.Stauts, no exported field for the default value")
+	assert.ErrorAs(t, New(WithDefault[withUnexported]("secret", "x")).Map(src{}, &withUnexported{}), &configErr)
+}
+
+func TestMap_CallbackPipeline(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Username string
+		Age      string
+	}
+
+	type dst struct {
+		Username string `smapper:",callback:trim,callback:lower"`
+		Age      int    `smapper:",callback:trim,callback:atoi,callback:double"`
+	}
+
+	var types []reflect.Type
+
+	mapper := New(WithCallbacks(
+		NewCallback("trim", func(src, dst reflect.Type, v any) (any, error) {
+			return strings.TrimSpace(v.(string)), nil
+		}),
+		NewCallback("lower", func(src, dst reflect.Type, v any) (any, error) {
+			return strings.ToLower(v.(string)), nil
+		}),
+		NewCallback("atoi", func(src, dst reflect.Type, v any) (any, error) {
+			return strconv.Atoi(v.(string))
+		}),
+		NewCallback("double", func(src, dst reflect.Type, v any) (any, error) {
+			types = append(types, src)
+
+			return v.(int) * 2, nil
+		}),
+	))
+
+	d := &dst{}
+	assert.NoError(t, mapper.Map(src{"  Alice ", " 21"}, d))
+	assert.Equal(t, "alice", d.Username)
+	assert.Equal(t, 42, d.Age)
+	// each callback receives the type of the previous one's output
+	assert.Equal(t, []reflect.Type{reflect.TypeOf(0)}, types)
+
+	var callbackErr *CallbackError
+	err := mapper.Map(src{"alice", "twenty"}, &dst{})
+	assert.ErrorAs(t, err, &callbackErr)
+	assert.Equal(t, "atoi", callbackErr.Callback())
+	assert.Equal(t, 2, callbackErr.Step())
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+}
+
+func TestMap_TypedCallbacks(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		CreatedAt string
+		Tags      string
+	}
+
+	type dst struct {
+		CreatedAt time.Time `smapper:",callback:parse_date"`
+		Tags      []string  `smapper:",callback:trim,callback:split"`
+	}
+
+	parseDate := NewTypedCallback("parse_date", func(s string) (time.Time, error) {
+		return time.Parse(time.DateOnly, s)
+	})
+	trim := NewTypedCallback("trim", func(s string) (string, error) {
+		return strings.TrimSpace(s), nil
+	})
+	split := NewTypedCallback("split", func(s string) ([]string, error) {
+		return strings.Split(s, ","), nil
+	})
+
+	mapper := New(WithCallbacks(parseDate, trim, split))
+
+	d := &dst{}
+	assert.NoError(t, mapper.Map(src{"2024-03-01", " go,mapper "}, d))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), d.CreatedAt)
+	assert.Equal(t, []string{"go", "mapper"}, d.Tags)
+
+	var configErr *ConfigError
+
+	// the source field's type does not match the callback's input
+	type intSrc struct {
+		CreatedAt int64
+	}
+
+	assert.ErrorAs(t, mapper.Map(intSrc{42}, &dst{}), &configErr)
+	assert.ErrorContains(t, configErr, "callback parse_date (step 1) wants string, got int64")
+
+	// the callbacks' types do not match each other
+	type badPipeline struct {
+		Tags []string `smapper:",callback:split,callback:trim"`
+	}
+
+	assert.ErrorAs(t, mapper.Map(src{Tags: "go"}, &badPipeline{}), &configErr)
+	assert.ErrorContains(t, configErr, "callback trim (step 2) wants string, got []string")
+
+	// the callback's output cannot be converted to the destination field's type
+	type badOutput struct {
+		CreatedAt string `smapper:",callback:parse_date"`
+	}
+
+	assert.ErrorAs(t, mapper.Map(src{CreatedAt: "2024-03-01"}, &badOutput{}), &configErr)
+
+	// named types are not the callback's input type, even if they're assignable to it
+	type tags []string
+
+	type namedSrc struct {
+		Tags tags
+	}
+
+	type joined struct {
+		Tags string `smapper:",callback:join"`
+	}
+
+	join := NewTypedCallback("join", func(s []string) (string, error) {
+		return strings.Join(s, ","), nil
+	})
+
+	_, err := Explain[namedSrc, joined](New(WithCallbacks(join)))
+	assert.ErrorAs(t, err, &configErr)
+	assert.ErrorContains(t, configErr, "callback join (step 1) wants []string, got smapper.tags")
+
+	// values that implement an interface input are accepted
+	stringer := NewTypedCallback("stringer", func(s fmt.Stringer) (string, error) {
+		return s.String(), nil
+	})
+
+	type durationSrc struct {
+		Timeout time.Duration
+	}
+
+	type durationDst struct {
+		Timeout string `smapper:",callback:stringer"`
+	}
+
+	dd := &durationDst{}
+	assert.NoError(t, New(WithCallbacks(stringer)).Map(durationSrc{time.Second}, dd))
+	assert.Equal(t, "1s", dd.Timeout)
+}
+
+func TestMap_ParamCallbacks(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Bio       string
+		CreatedAt time.Time
+	}
+
+	type dst struct {
+		Bio       string `smapper:",callback:truncate=5"`
+		CreatedAt string `smapper:",callback:format='Jan 2, 2006'"`
+	}
+
+	mapper := New(WithCallbacks(
+		NewParamCallback("truncate", func(src, dst reflect.Type, v any, param string) (any, error) {
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				return nil, err
+			}
+
+			s := v.(string)
+			if len(s) > n {
+				s = s[:n]
+			}
+
+			return s, nil
+		}),
+		NewParamCallback("format", func(src, dst reflect.Type, v any, param string) (any, error) {
+			return v.(time.Time).Format(param), nil
+		}),
+		NewCallback("double", func(src, dst reflect.Type, v any) (any, error) {
+			return v.(int) * 2, nil
+		}),
+	))
+
+	d := &dst{}
+	assert.NoError(t, mapper.Map(src{"gopher and mapper", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, d))
+	assert.Equal(t, "gophe", d.Bio)
+	assert.Equal(t, "Mar 1, 2024", d.CreatedAt)
+
+	plan, err := Explain[src, dst](mapper)
+	assert.NoError(t, err)
+	assert.Equal(t, "format=Jan 2, 2006", plan.Fields[1].Callback)
+
+	// callbacks that are not registered using NewParamCallback don't accept params
+	type badParam struct {
+		Bio int `smapper:",callback:double=3"`
+	}
+
+	assert.Error(t, mapper.Map(struct{ Bio int }{1}, &badParam{}))
+}
+
+func TestMap_Computes(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Price    int
+		Quantity int
+	}
+
+	type order struct {
+		FirstName string
+		LastName  string
+		Items     []item
+	}
+
+	type orderDTO struct {
+		FullName string `smapper:"-,compute:full_name,required"`
+		// Count is computed after Items is mapped, even though it comes before it
+		Count int   `smapper:"-,compute:count"`
+		Total int64 `smapper:"-,compute:total,gt=0"`
+		Items []item
+	}
+
+	mapper := New(WithComputes(
+		NewCompute("full_name", func(src, dst reflect.Value) (any, error) {
+			return strings.TrimSpace(src.FieldByName("FirstName").String() + " " + src.FieldByName("LastName").String()), nil
+		}),
+		NewTypedCompute("count", func(src order, dst *orderDTO) (int, error) {
+			return len(dst.Items), nil
+		}),
+		NewTypedCompute("total", func(src order, dst *orderDTO) (int, error) {
+			var total int
+			for _, item := range src.Items {
+				total += item.Price * item.Quantity
+			}
+
+			return total, nil
+		}),
+	), WithStrict())
+
+	d := &orderDTO{}
+	assert.NoError(t, mapper.Map(order{"Alice", "Smith", []item{{10, 2}, {5, 1}}}, d))
+	assert.Equal(t, "Alice Smith", d.FullName)
+	assert.Equal(t, 2, d.Count)
+	assert.EqualValues(t, 25, d.Total)
+
+	// validators are executed for the computed values
+	assert.Error(t, mapper.Map(order{FirstName: "Alice"}, &orderDTO{}))
+
+	plan, err := Explain[order, orderDTO](mapper)
+	assert.NoError(t, err)
+	assert.Equal(t, ConversionCompute, plan.Fields[2].Conversion)
+	assert.Equal(t, "total", plan.Fields[2].Compute)
+
+	// typed compute functions check the source and destination types
+	type otherDTO struct {
+		Count int `smapper:"-,compute:count"`
+	}
+
+	var configErr *ConfigError
+	assert.ErrorAs(t, mapper.Map(order{}, &otherDTO{}), &configErr)
+
+	// the fields that are read only by compute functions are unused, unless the functions declare them
+	type nameDTO struct {
+		FullName string `smapper:"-,compute:full_name"`
+		Items    []item
+	}
+
+	fullName := func(src order, dst *nameDTO) (string, error) {
+		return src.FirstName + " " + src.LastName, nil
+	}
+
+	var unmappedErr *UnmappedFieldsError
+	strict := New(WithComputes(NewTypedCompute("full_name", fullName)), WithStrictSource())
+	assert.ErrorAs(t, strict.Map(order{}, &nameDTO{}), &unmappedErr)
+	assert.ErrorContains(t, unmappedErr, "unused source fields: FirstName, LastName")
+
+	strict = New(WithComputes(NewTypedCompute("full_name", fullName).WithReads("FirstName", "last_name")),
+		WithStrictSource(), WithNamingStrategy(SnakeCase))
+	assert.NoError(t, strict.Map(order{}, &nameDTO{}))
+
+	strict = New(WithComputes(NewTypedCompute("full_name", fullName).WithReads("MiddleName")), WithStrictSource())
+	assert.ErrorAs(t, strict.Map(order{}, &nameDTO{}), &configErr)
+	assert.ErrorContains(t, configErr, "compute full_name reads MiddleName, but order has no such field")
+}
+
+func TestMapper_With(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Name   string
+		Status string
+	}
+
+	type dst struct {
+		Name   string `smapper:",callback:decorate"`
+		Status string `smapper:",enum=status"`
+	}
+
+	decorate := func(prefix string) *Callback {
+		return NewCallback("decorate", func(src, dst reflect.Type, v any) (any, error) {
+			return prefix + v.(string), nil
+		})
+	}
+
+	base := New(WithCallbacks(decorate("base:")), WithEnum("status", "active"))
+
+	// only the config is changed, so the plans are shared
+	strict := base.With(WithStrict())
+	assert.True(t, strict.Strict)
+	assert.False(t, base.Strict)
+	assert.Same(t, base.plans, strict.plans)
+
+	// the registrations are changed, so the plans are not shared, and base is not changed
+	derived := base.With(WithCallbacks(decorate("derived:")), WithEnum("status", "disabled"))
+	assert.NotSame(t, base.plans, derived.plans)
+
+	d := &dst{}
+	assert.NoError(t, derived.Map(src{"alice", "disabled"}, d))
+	assert.Equal(t, "derived:alice", d.Name)
+
+	d = &dst{}
+	assert.Error(t, base.Map(src{"alice", "disabled"}, d))
+	assert.NoError(t, base.Map(src{"alice", "active"}, d))
+	assert.Equal(t, "base:alice", d.Name)
+}
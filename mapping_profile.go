@@ -0,0 +1,347 @@
+package smapper
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// MappingProfile contains the mapping rules of Src to Dst that are loaded from a mapping document using LoadProfile,
+// it's applied to a mapper using WithMappingProfile.
+type MappingProfile struct {
+	Source      reflect.Type
+	Destination reflect.Type
+	// rules contains the rules of the fields, keyed by the type pairs that the fields are mapped for,
+	// nested destination paths have rules for the nested structs' type pairs
+	rules map[typePair]map[string]fieldRule
+}
+
+// fieldRule replaces the tag of a destination field when it's mapped for a type pair.
+type fieldRule struct {
+	// values are the values of the tag, just like the values of a smapper tag
+	values []string
+	// renameOnly is true for the parents of the nested paths that don't have rules of their own,
+	// only the field name of their tags is replaced
+	renameOnly bool
+}
+
+// mappingDocument is the format of the mapping documents, JSON documents are valid YAML documents too.
+type mappingDocument struct {
+	Fields []mappingField `yaml:"fields"`
+	Ignore []string       `yaml:"ignore"`
+}
+
+type mappingField struct {
+	Source      string   `yaml:"source"`
+	Destination string   `yaml:"destination"`
+	Callbacks   []string `yaml:"callbacks"`
+	Validators  []string `yaml:"validators"`
+	Default     *string  `yaml:"default"`
+}
+
+// LoadProfile reads a YAML or JSON mapping document, and returns the mapping rules of Src to Dst, e.g.
+//
+//	fields:
+//	  - source: user_name
+//	    destination: Name
+//	    callbacks: [trim]
+//	    validators: [required, gte=3]
+//	  - source: location.town
+//	    destination: Address.City
+//	    default: Tehran
+//	ignore: [Password]
+//
+// the paths are dot separated field names, and they're matched to the fields regardless of their case style
+// (e.g. user_name matches UserName). the parents of a destination field are mapped from the first fields of
+// its source path, and the rest of the source path is the field's source, so nested sources can be flattened
+// (e.g. location.town to City), but a source path cannot be shorter than its destination path. the source path
+// of a rule is the same as its destination path if it's omitted. the document is validated against Src and Dst:
+// the paths must exist (except the omitted source paths of the fields with defaults), the nested paths must go
+// through structs, and the defaults must be convertible to the fields' types. the callbacks and validators are
+// checked when the profile is applied to a mapper, see WithMappingProfile.
+func LoadProfile[Src, Dst any](r io.Reader) (*MappingProfile, error) {
+	src := reflect.TypeOf((*Src)(nil)).Elem()
+	dst := reflect.TypeOf((*Dst)(nil)).Elem()
+
+	err := validateInputTypes(src, reflect.PointerTo(dst))
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+
+	var doc mappingDocument
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &Error{msg: "invalid mapping profile, the document is empty"}
+		}
+
+		return nil, &Error{msg: fmt.Sprintf("invalid mapping profile, %s", err)}
+	}
+
+	res := &MappingProfile{
+		Source:      src,
+		Destination: dst,
+		rules:       make(map[typePair]map[string]fieldRule),
+	}
+
+	for _, f := range doc.Fields {
+		if err := res.addField(f, false); err != nil {
+			return nil, &Error{msg: fmt.Sprintf("invalid mapping profile, field %s: %s", f.Destination, err)}
+		}
+	}
+
+	for _, path := range doc.Ignore {
+		if err := res.addField(mappingField{Destination: path}, true); err != nil {
+			return nil, &Error{msg: fmt.Sprintf("invalid mapping profile, ignored field %s: %s", path, err)}
+		}
+	}
+
+	return res, nil
+}
+
+// addField resolves the paths of f, and adds the rules of the field and its parents.
+func (p *MappingProfile) addField(f mappingField, ignored bool) error {
+	if f.Destination == "" {
+		return errors.New("destination is required")
+	}
+
+	dstPath := strings.Split(f.Destination, ".")
+
+	srcPath := dstPath
+	if f.Source != "" {
+		srcPath = strings.Split(f.Source, ".")
+	}
+
+	// the parents of the destination field are mapped from the first source fields, and the remaining
+	// source fields are the path of the field's source
+	if len(srcPath) < len(dstPath) {
+		return fmt.Errorf("source %s is shorter than the destination", f.Source)
+	}
+
+	pair := typePair{src: p.Source, dst: p.Destination}
+
+	for i, name := range dstPath {
+		dstField, err := pathField(pair.dst, name)
+		if err != nil {
+			return err
+		}
+
+		last := i == len(dstPath)-1
+
+		if last && ignored {
+			return p.addRule(pair, dstField.Name, fieldRule{values: []string{ignoreTag}})
+		}
+
+		if !last {
+			srcField, err := pathField(pair.src, srcPath[i])
+			if err != nil {
+				return err
+			}
+
+			if err := p.addRule(pair, dstField.Name, fieldRule{values: []string{srcField.Name}, renameOnly: true}); err != nil {
+				return err
+			}
+
+			pair.dst, err = structElem(dstField)
+			if err != nil {
+				return err
+			}
+
+			pair.src, err = structElem(srcField)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		source, err := sourcePath(pair.src, srcPath[i:])
+		if err != nil {
+			// a field without a source can still be filled with its default value
+			if f.Source != "" || f.Default == nil {
+				return err
+			}
+
+			source = dstField.Name
+		}
+
+		values := []string{source}
+		for _, c := range f.Callbacks {
+			values = append(values, callbackTag+c)
+		}
+
+		if f.Default != nil {
+			_, err := (&Mapper{}).convertDefault(pair.dst, dstField, reflect.ValueOf(*f.Default))
+			if err != nil {
+				return err
+			}
+
+			values = append(values, defaultTag+*f.Default)
+		}
+
+		values = append(values, f.Validators...)
+
+		return p.addRule(pair, dstField.Name, fieldRule{values: values})
+	}
+
+	return nil
+}
+
+// addRule adds the rule of a field, a field can have only one rule, but its rule can be shared by several paths
+// as long as they're mapped from the same source field.
+func (p *MappingProfile) addRule(pair typePair, field string, rule fieldRule) error {
+	rules := p.rules[pair]
+	if rules == nil {
+		rules = make(map[string]fieldRule)
+		p.rules[pair] = rules
+	}
+
+	existing, found := rules[field]
+	if !found {
+		rules[field] = rule
+
+		return nil
+	}
+
+	if (existing.values[0] == ignoreTag) != (rule.values[0] == ignoreTag) {
+		return fmt.Errorf("%s.%s is both ignored and mapped", pair.dst.Name(), field)
+	}
+
+	if existing.values[0] != rule.values[0] {
+		return fmt.Errorf("%s.%s is mapped from both %s and %s", pair.dst.Name(), field, existing.values[0], rule.values[0])
+	}
+
+	switch {
+	case !existing.renameOnly && !rule.renameOnly:
+		return fmt.Errorf("%s.%s has more than one rule", pair.dst.Name(), field)
+	case existing.renameOnly:
+		rules[field] = rule
+	}
+
+	return nil
+}
+
+// pathField returns the exported field of t with the given name, names are matched regardless of their case style.
+func pathField(t reflect.Type, name string) (reflect.StructField, error) {
+	if f, found := findField(t, name, nil); found {
+		return f, nil
+	}
+
+	if f, found := findField(t, name, SnakeCase); found {
+		return f, nil
+	}
+
+	return reflect.StructField{}, fmt.Errorf("%s has no field %s", t.Name(), name)
+}
+
+// sourcePath resolves the path of a field's source, and returns it using the names of the fields,
+// the fields in the middle of the path must be structs or pointers to structs.
+func sourcePath(t reflect.Type, path []string) (string, error) {
+	names := make([]string, 0, len(path))
+
+	for i, name := range path {
+		f, err := pathField(t, name)
+		if err != nil {
+			return "", err
+		}
+
+		names = append(names, f.Name)
+
+		if i == len(path)-1 {
+			break
+		}
+
+		t = f.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct {
+			return "", fmt.Errorf("%s is not a struct", f.Name)
+		}
+	}
+
+	return strings.Join(names, "."), nil
+}
+
+// structElem returns the struct type of a field in the middle of a path, it can be a struct, or a pointer,
+// a slice, an array or a map of structs.
+func structElem(f reflect.StructField) (reflect.Type, error) {
+	t := f.Type
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", f.Name)
+	}
+
+	return t, nil
+}
+
+// mappingTagValues returns the values of the field's tag when it's mapped from src to dst, the rules of the
+// mapping profiles replace the field's tag.
+func (m *Mapper) mappingTagValues(src, dst reflect.Type, f reflect.StructField) []string {
+	rule, found := m.mappingRules[typePair{src: src, dst: dst}][f.Name]
+	if !found {
+		return m.getTagValues(f)
+	}
+
+	if rule.renameOnly {
+		values := m.getTagValues(f)
+		values[0] = rule.values[0]
+
+		return values
+	}
+
+	return append([]string(nil), rule.values...)
+}
+
+// checkMappingRules checks if the callbacks and validators of the mapping profiles' rules exist, so the typos
+// in the documents are found when the mapper is created, instead of when a mapping is planned.
+func (m *Mapper) checkMappingRules() error {
+	type ruleKey struct {
+		pair  typePair
+		field string
+	}
+
+	var keys []ruleKey
+	for pair, rules := range m.mappingRules {
+		for field, rule := range rules {
+			if !rule.renameOnly {
+				keys = append(keys, ruleKey{pair: pair, field: field})
+			}
+		}
+	}
+
+	// the rules are checked in order, so the same error is returned for the same rules
+	slices.SortFunc(keys, func(a, b ruleKey) int {
+		return strings.Compare(a.pair.dst.String()+"."+a.field, b.pair.dst.String()+"."+b.field)
+	})
+
+	for _, key := range keys {
+		if _, err := m.parseTagValues(m.mappingRules[key.pair][key.field].values); err != nil {
+			msg := err.Error()
+
+			var e *Error
+			if errors.As(err, &e) {
+				msg = e.msg
+			}
+
+			return &ConfigError{parentType: key.pair.dst, fieldName: key.field, msg: msg}
+		}
+	}
+
+	return nil
+}
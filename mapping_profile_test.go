@@ -0,0 +1,257 @@
+package smapper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type partnerLocation struct {
+	Town    string
+	Country string
+}
+
+type partnerPayload struct {
+	UserName string
+	Mail     string
+	Secret   string
+	Location partnerLocation
+}
+
+type profileAddress struct {
+	City    string
+	Country string
+}
+
+type profileUser struct {
+	Name     string `smapper:"username"`
+	Email    string
+	Password string
+	Address  profileAddress
+}
+
+const partnerYAML = `
+fields:
+  - source: user_name
+    destination: Name
+    callbacks: [upper]
+    validators: [required, gte=3]
+  - source: mail
+    destination: email
+  - source: location.town
+    destination: Address.City
+    default: Tehran
+ignore: [Password]
+`
+
+func TestLoadProfile(t *testing.T) {
+	t.Parallel()
+
+	profile, err := LoadProfile[partnerPayload, profileUser](strings.NewReader(partnerYAML))
+	assert.NoError(t, err)
+
+	mapper := New(WithMappingProfile(profile), WithCallbacks(NewCallback("upper", func(src, dst reflect.Type, v any) (any, error) {
+		return strings.ToUpper(v.(string)), nil
+	})))
+
+	payload := partnerPayload{
+		UserName: "alice",
+		Mail:     "alice@example.com",
+		Secret:   "secret",
+		Location: partnerLocation{Country: "Iran"},
+	}
+
+	u := profileUser{}
+	assert.NoError(t, mapper.Map(payload, &u))
+	assert.Equal(t, profileUser{
+		Name:    "ALICE",
+		Email:   "alice@example.com",
+		Address: profileAddress{City: "Tehran", Country: "Iran"},
+	}, u)
+
+	assert.Error(t, mapper.Map(partnerPayload{UserName: "al"}, &profileUser{}))
+
+	// the rules are used only for the profile's types, the tags are used for the other types
+	type request struct {
+		Username string
+		Password string
+	}
+
+	u = profileUser{}
+	assert.NoError(t, mapper.Map(request{"bob", "secret"}, &u))
+	assert.Equal(t, profileUser{Name: "bob", Password: "secret"}, u)
+
+	// JSON documents are loaded too
+	profile, err = LoadProfile[partnerPayload, profileUser](strings.NewReader(`{"fields": [{"source": "UserName", "destination": "Name"}]}`))
+	assert.NoError(t, err)
+
+	u = profileUser{}
+	assert.NoError(t, New(WithMappingProfile(profile)).Map(payload, &u))
+	assert.Equal(t, "alice", u.Name)
+}
+
+func TestWithMappingProfile_UnknownNames(t *testing.T) {
+	t.Parallel()
+
+	doc := `
+fields:
+  - source: user_name
+    destination: Name
+    callbacks: [uper]
+  - source: mail
+    destination: Email
+    validators: [emial]
+`
+
+	profile, err := LoadProfile[partnerPayload, profileUser](strings.NewReader(doc))
+	assert.NoError(t, err)
+
+	// the names are checked when the mapper is created, before any mapping
+	var configErr *ConfigError
+	mapper := New(WithMappingProfile(profile))
+	assert.ErrorAs(t, mapper.Map(partnerPayload{}, &profileUser{}), &configErr)
+	assert.ErrorContains(t, configErr, "invalid configuration for profileUser.Email")
+
+	_, err = Explain[profileUser, profileUser](mapper)
+	assert.ErrorAs(t, err, &configErr)
+
+	// the names are looked up in the mapper's options and its registry
+	registry := NewRegistry()
+	registry.RegisterCallback(NewCallback("uper", func(src, dst reflect.Type, v any) (any, error) {
+		return v, nil
+	}))
+
+	mapper = New(WithMappingProfile(profile), WithRegistry(registry))
+	assert.ErrorContains(t, mapper.Map(partnerPayload{}, &profileUser{}), "emial")
+
+	mapper = mapper.With(WithIgnoreMissingValidators())
+	assert.NoError(t, mapper.Map(partnerPayload{}, &profileUser{}))
+}
+
+func TestWithMappingProfile_Nil(t *testing.T) {
+	t.Parallel()
+
+	var configErr *ConfigError
+	mapper := New(WithMappingProfile(nil))
+	assert.ErrorAs(t, mapper.Map(partnerPayload{}, &profileUser{}), &configErr)
+	assert.EqualError(t, configErr, "smapper: invalid configuration, mapping profile cannot be nil")
+
+	// the error is kept by the derived mappers
+	assert.ErrorAs(t, mapper.With(WithStrict()).Validate(profileUser{}), &configErr)
+}
+
+func TestLoadProfile_NestedSources(t *testing.T) {
+	t.Parallel()
+
+	type flatUser struct {
+		Name    string
+		City    string
+		Country string
+	}
+
+	type nestedPayload struct {
+		User     partnerPayload
+		Location *partnerLocation
+	}
+
+	doc := `
+fields:
+  - source: location.town
+    destination: City
+  - source: user.location.country
+    destination: Country
+  - source: user.user_name
+    destination: Name
+`
+
+	profile, err := LoadProfile[nestedPayload, flatUser](strings.NewReader(doc))
+	assert.NoError(t, err)
+
+	mapper := New(WithMappingProfile(profile))
+
+	u := flatUser{}
+	payload := nestedPayload{
+		User:     partnerPayload{UserName: "alice", Location: partnerLocation{Country: "Iran"}},
+		Location: &partnerLocation{Town: "Tehran"},
+	}
+	assert.NoError(t, mapper.Map(payload, &u))
+	assert.Equal(t, flatUser{Name: "alice", City: "Tehran", Country: "Iran"}, u)
+
+	// nil pointers in the middle of a source path leave the field unmapped
+	u = flatUser{}
+	assert.NoError(t, mapper.Map(nestedPayload{User: payload.User}, &u))
+	assert.Equal(t, flatUser{Name: "alice", Country: "Iran"}, u)
+}
+
+func TestLoadProfile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "empty",
+			doc:  "",
+			want: "the document is empty",
+		},
+		{
+			name: "unknown key",
+			doc:  "fields: [{source: mail, target: Email}]",
+			want: "field target not found",
+		},
+		{
+			name: "unknown destination",
+			doc:  "fields: [{source: mail, destination: Phone}]",
+			want: "profileUser has no field Phone",
+		},
+		{
+			name: "unknown source",
+			doc:  "fields: [{source: phone, destination: Email}]",
+			want: "partnerPayload has no field phone",
+		},
+		{
+			name: "short source",
+			doc:  "fields: [{source: town, destination: Address.City}]",
+			want: "source town is shorter than the destination",
+		},
+		{
+			name: "source through a non-struct",
+			doc:  "fields: [{source: mail.host, destination: Email}]",
+			want: "Mail is not a struct",
+		},
+		{
+			name: "not a struct",
+			doc:  "fields: [{source: mail.host, destination: Email.Host}]",
+			want: "Email is not a struct",
+		},
+		{
+			name: "invalid default",
+			doc:  "fields: [{destination: Address, default: home}]",
+			want: "invalid default value",
+		},
+		{
+			name: "duplicate",
+			doc:  "fields: [{source: mail, destination: Email}, {source: user_name, destination: Email}]",
+			want: "profileUser.Email is mapped from both Mail and UserName",
+		},
+		{
+			name: "ignored and mapped",
+			doc:  "fields: [{source: mail, destination: Email}]\nignore: [Email]",
+			want: "profileUser.Email is both ignored and mapped",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := LoadProfile[partnerPayload, profileUser](strings.NewReader(tt.doc))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package smapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defaultMessage is the message of the validators that have no message templates.
+const defaultMessage = "{field} is not valid"
+
+// defaultMessages are the message templates of the built-in validators, they can be replaced using WithMessages.
+var defaultMessages = map[string]string{
+	"required":  "{field} is required",
+	"unique":    "{field} must contain unique values",
+	"len":       "{field} must have length {param}",
+	"eq":        "{field} must be equal to {param}",
+	"ne":        "{field} must not be equal to {param}",
+	"gt":        "{field} must be greater than {param}",
+	"gte":       "{field} must be at least {param}",
+	"lt":        "{field} must be less than {param}",
+	"lte":       "{field} must be at most {param}",
+	"oneof":     "{field} must be one of {param}",
+	"enum":      "{field} must be one of the values of {param}",
+	"email":     "{field} must be a valid email address",
+	"url":       "{field} must be a valid URL",
+	"uri":       "{field} must be a valid URI",
+	"uuid":      "{field} must be a valid UUID",
+	"ip":        "{field} must be a valid IP address",
+	"ipv4":      "{field} must be a valid IPv4 address",
+	"ipv6":      "{field} must be a valid IPv6 address",
+	"cidr":      "{field} must be a valid CIDR notation",
+	"hostname":  "{field} must be a valid hostname",
+	"regexp":    "{field} must match {param}",
+	"alpha":     "{field} must contain only letters",
+	"alphanum":  "{field} must contain only letters and numbers",
+	"numeric":   "{field} must be a number",
+	"ascii":     "{field} must contain only ASCII characters",
+	"lowercase": "{field} must be lowercase",
+	"uppercase": "{field} must be uppercase",
+	"contains":  "{field} must contain {param}",
+	"prefix":    "{field} must start with {param}",
+	"suffix":    "{field} must end with {param}",
+	"json":      "{field} must be a valid JSON",
+
+	// cross-field validators
+	"eqfield":          "{field} must be equal to {param}",
+	"nefield":          "{field} must not be equal to {param}",
+	"gtfield":          "{field} must be greater than {param}",
+	"gtefield":         "{field} must be greater than or equal to {param}",
+	"ltfield":          "{field} must be less than {param}",
+	"ltefield":         "{field} must be less than or equal to {param}",
+	"required_if":      "{field} is required",
+	"required_unless":  "{field} is required",
+	"required_with":    "{field} is required",
+	"required_without": "{field} is required",
+	"excluded_if":      "{field} must be empty",
+}
+
+// Translator translates the message templates of validation errors, key is the name of the failed validator,
+// or the field's message if it's set in the tag (e.g. `smapper:",required,msg=user.name.required"`).
+// it returns false if there's no translation for the key in the given locale.
+type Translator interface {
+	Translate(locale, key string) (string, bool)
+}
+
+// TranslatorFunc is a function that implements Translator.
+type TranslatorFunc func(locale, key string) (string, bool)
+
+func (fn TranslatorFunc) Translate(locale, key string) (string, bool) {
+	return fn(locale, key)
+}
+
+// message returns the message template of the given validator, templates that are registered in the mapper
+// take precedence over the default ones.
+func (m *Mapper) message(name string) string {
+	if msg, found := m.messages[name]; found {
+		return msg
+	}
+
+	if msg, found := defaultMessages[name]; found {
+		return msg
+	}
+
+	return defaultMessage
+}
+
+// setMessages sets the message templates of the validators (including the dive ones), msg is the field's
+// message that is set in the tag, it's used for all the validators of the field if it's not empty.
+func (m *Mapper) setMessages(validators []validator, d *dive, msg string) {
+	for i := range validators {
+		validators[i].key, validators[i].message = validators[i].name, m.message(validators[i].name)
+		if msg != "" {
+			validators[i].key, validators[i].message = msg, msg
+		}
+	}
+
+	if d != nil {
+		m.setMessages(d.keys, nil, msg)
+		m.setMessages(d.validators, d.dive, msg)
+	}
+}
+
+// renderMessage replaces the placeholders of the template with the error's field name, path, param and value.
+func renderMessage(template string, e *ValidationError) string {
+	value := "<nil>"
+	if v := reflect.Indirect(e.value.Value); v.IsValid() && v.CanInterface() {
+		value = fmt.Sprint(v.Interface())
+	}
+
+	return strings.NewReplacer(
+		"{field}", e.value.FieldName,
+		"{path}", e.value.path,
+		"{param}", e.param,
+		"{value}", value,
+	).Replace(template)
+}
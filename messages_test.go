@@ -0,0 +1,83 @@
+package smapper
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidationError_Message(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Name   string
+		Age    int
+		Emails []string
+	}
+
+	type dst struct {
+		Name   string   `smapper:",required,msg='please enter your name'"`
+		Age    int      `smapper:",gte=18"`
+		Emails []string `smapper:",dive,email"`
+	}
+
+	message := func(mapper *Mapper, s src) string {
+		var validationErr *ValidationError
+		if !errors.As(mapper.Map(s, &dst{}), &validationErr) {
+			return ""
+		}
+
+		return validationErr.Message()
+	}
+
+	mapper := New()
+
+	assert.Equal(t, "please enter your name", message(mapper, src{Age: 20}))
+	assert.Equal(t, "Age must be at least 18", message(mapper, src{Name: "alice", Age: 16}))
+	assert.Equal(t, "Emails[1] must be a valid email address",
+		message(mapper, src{Name: "alice", Age: 20, Emails: []string{"alice@example.com", "alice"}}))
+
+	mapper = New(WithMessages(map[string]string{
+		"gte": "{path} is {value}, but it must be {param} or more",
+	}))
+
+	assert.Equal(t, "dst.Age is 16, but it must be 18 or more", message(mapper, src{Name: "alice", Age: 16}))
+}
+
+func TestValidationError_Translate(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Name string
+		Age  int
+	}
+
+	type dst struct {
+		Name string `smapper:",required,msg=name.required"`
+		Age  int    `smapper:",gte=18"`
+	}
+
+	translations := map[string]map[string]string{
+		"fr": {
+			"gte":           "{field} doit être au moins {param}",
+			"name.required": "le nom est obligatoire",
+		},
+	}
+
+	tr := TranslatorFunc(func(locale, key string) (string, bool) {
+		msg, found := translations[locale][key]
+
+		return msg, found
+	})
+
+	mapper := New()
+
+	var validationErr *ValidationError
+
+	assert.ErrorAs(t, mapper.Map(src{Name: "alice", Age: 16}, &dst{}), &validationErr)
+	assert.Equal(t, "Age doit être au moins 18", validationErr.Translate(tr, "fr"))
+	assert.Equal(t, "Age must be at least 18", validationErr.Translate(tr, "de"))
+
+	assert.ErrorAs(t, mapper.Map(src{Age: 20}, &dst{}), &validationErr)
+	assert.Equal(t, "le nom est obligatoire", validationErr.Translate(tr, "fr"))
+}
@@ -0,0 +1,180 @@
+package smapper
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy converts a field name or a field name in a tag into a canonical form, a destination field
+// matches a source field if both of their names are converted to the same value.
+type NamingStrategy func(name string) string
+
+var (
+	// SnakeCase matches fields using their snake_case form (e.g. UserID, userId and user_id are all user_id).
+	SnakeCase NamingStrategy = func(name string) string {
+		return strings.Join(lowerWords(name), "_")
+	}
+	// KebabCase matches fields using their kebab-case form (e.g. UserID, userId and user-id are all user-id).
+	KebabCase NamingStrategy = func(name string) string {
+		return strings.Join(lowerWords(name), "-")
+	}
+	// CamelCase matches fields using their camelCase form (e.g. UserID, user_id and userId are all userId).
+	CamelCase NamingStrategy = func(name string) string {
+		words := lowerWords(name)
+		for i := 1; i < len(words); i++ {
+			words[i] = toPascalCase(words[i])
+		}
+
+		return strings.Join(words, "")
+	}
+	// CaseInsensitive matches fields regardless of their case (e.g. UserID, userid and USERID).
+	CaseInsensitive NamingStrategy = strings.ToLower
+	// AcronymAware matches fields using their Go form, where common acronyms are upper-cased
+	// (e.g. user_id, userId and UserID are all UserID).
+	AcronymAware NamingStrategy = func(name string) string {
+		words := lowerWords(name)
+		for i, w := range words {
+			if acronyms[w] {
+				words[i] = strings.ToUpper(w)
+			} else {
+				words[i] = toPascalCase(w)
+			}
+		}
+
+		return strings.Join(words, "")
+	}
+)
+
+// acronyms contains the common initialisms that Go names use in upper case.
+var acronyms = map[string]bool{
+	"acl": true, "api": true, "ascii": true, "cpu": true, "css": true, "dns": true, "eof": true,
+	"guid": true, "html": true, "http": true, "https": true, "id": true, "ip": true, "json": true,
+	"lhs": true, "qps": true, "ram": true, "rhs": true, "rpc": true, "sla": true, "smtp": true,
+	"sql": true, "ssh": true, "tcp": true, "tls": true, "ttl": true, "udp": true, "ui": true,
+	"uid": true, "uuid": true, "uri": true, "url": true, "utf8": true, "vm": true, "xml": true,
+	"xmpp": true, "xsrf": true, "xss": true,
+}
+
+// lowerWords splits the name into its lower-cased words, words are separated by '_', '-', ' ' or
+// a change of case, a run of upper-case letters is considered as one word (e.g. "HTTPServer" is "http", "server").
+func lowerWords(name string) []string {
+	var words []string
+
+	runes := []rune(name)
+	start := 0
+
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && !isSeparator(runes[i]) && !isWordStart(runes, i) {
+			continue
+		}
+
+		if i > start {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+		}
+
+		start = i
+		if i < len(runes) && isSeparator(runes[i]) {
+			start = i + 1
+		}
+	}
+
+	return words
+}
+
+func isSeparator(r rune) bool {
+	return r == '_' || r == '-' || r == ' '
+}
+
+func isWordStart(runes []rune, i int) bool {
+	if i == 0 || !unicode.IsUpper(runes[i]) {
+		return false
+	}
+
+	prev := runes[i-1]
+	if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+		return true
+	}
+
+	// the last upper-case letter of an acronym starts a new word if it's followed by a lower-case letter
+	return unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+// namingStrategy returns the naming strategy for mapping src to dst, the destination type's strategy
+// takes precedence over the source type's strategy, and both of them take precedence over the mapper's strategy.
+func (m *Mapper) namingStrategy(src, dst reflect.Type) NamingStrategy {
+	if s, found := m.structNaming[dst]; found {
+		return s
+	}
+
+	if s, found := m.structNaming[src]; found {
+		return s
+	}
+
+	return m.naming
+}
+
+// findField searches for the given field name in t, if a naming strategy is provided, the shallowest
+// exported field whose converted name is equal to the converted name is returned.
+func findField(t reflect.Type, name string, naming NamingStrategy) (reflect.StructField, bool) {
+	if naming == nil {
+		f, found := t.FieldByName(toPascalCase(name))
+		if !found || !f.IsExported() {
+			return reflect.StructField{}, false
+		}
+
+		return f, true
+	}
+
+	want := naming(name)
+
+	var (
+		res   reflect.StructField
+		found bool
+	)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (found && len(f.Index) >= len(res.Index)) {
+			continue
+		}
+
+		if naming(f.Name) == want {
+			res, found = f, true
+		}
+	}
+
+	return res, found
+}
+
+// findFieldPath searches for the given dot separated path of field names in t (e.g. "Location.Town"), each name
+// is searched using findField, and the fields in the middle of the path must be structs or pointers to structs.
+// the returned field's index is the index sequence of the whole path.
+func findFieldPath(t reflect.Type, path string, naming NamingStrategy) (reflect.StructField, bool) {
+	names := strings.Split(path, ".")
+
+	var index []int
+	for i, name := range names {
+		f, found := findField(t, name, naming)
+		if !found {
+			return reflect.StructField{}, false
+		}
+
+		index = append(index, f.Index...)
+
+		if i == len(names)-1 {
+			f.Index = index
+
+			return f, true
+		}
+
+		t = f.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct {
+			return reflect.StructField{}, false
+		}
+	}
+
+	return reflect.StructField{}, false
+}
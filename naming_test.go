@@ -0,0 +1,72 @@
+package smapper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name     string
+		strategy NamingStrategy
+		input    string
+		expected string
+	}
+
+	tests := []testCase{
+		{"snake case from pascal case", SnakeCase, "UserID", "user_id"},
+		{"snake case from camel case", SnakeCase, "userId", "user_id"},
+		{"snake case from snake case", SnakeCase, "user_id", "user_id"},
+		{"snake case with acronym", SnakeCase, "HTTPServerURL", "http_server_url"},
+		{"kebab case", KebabCase, "UserID", "user-id"},
+		{"camel case from snake case", CamelCase, "user_id", "userId"},
+		{"camel case from pascal case", CamelCase, "UserID", "userId"},
+		{"case insensitive", CaseInsensitive, "UserID", "userid"},
+		{"acronym aware from snake case", AcronymAware, "image_url", "ImageURL"},
+		{"acronym aware from camel case", AcronymAware, "userId", "UserID"},
+		{"acronym aware from pascal case", AcronymAware, "UserID", "UserID"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.strategy(test.input))
+		})
+	}
+}
+
+func TestMap_NamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		UserID   int
+		ImageURL string
+		FullName string
+	}
+
+	type dst struct {
+		UserId   int
+		ImageUrl string `smapper:"image_url"`
+		Name     string `smapper:"full_name"`
+	}
+
+	s := src{UserID: 42, ImageURL: "https://example.com", FullName: "admin"}
+
+	d := dst{}
+	assert.NoError(t, New().Map(s, &d))
+	assert.Empty(t, d, "should be empty because fields are matched by their exact names")
+
+	d = dst{}
+	assert.NoError(t, New(WithNamingStrategy(SnakeCase)).Map(s, &d))
+	assert.Equal(t, dst{UserId: 42, ImageUrl: "https://example.com", Name: "admin"}, d)
+
+	d = dst{}
+	assert.NoError(t, New(WithStructNamingStrategy[dst](AcronymAware)).Map(s, &d))
+	assert.Equal(t, dst{UserId: 42, ImageUrl: "https://example.com", Name: "admin"}, d)
+
+	// the destination's strategy takes precedence over the mapper's strategy
+	d = dst{}
+	assert.NoError(t, New(WithNamingStrategy(SnakeCase), WithStructNamingStrategy[dst](CaseInsensitive)).Map(s, &d))
+	assert.Equal(t, dst{UserId: 42}, d)
+}
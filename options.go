@@ -1,11 +1,23 @@
 package smapper
 
+import "reflect"
+
 type Option func(*Mapper)
 
 func WithCallbacks(callbacks ...*Callback) Option {
 	return func(mapper *Mapper) {
 		for _, callback := range callbacks {
-			mapper.callbacks[callback.Name] = callback.Func
+			mapper.callbacks[callback.Name] = callback
+		}
+	}
+}
+
+// WithComputes registers compute functions, they can be used for computing destination fields using the whole
+// source struct (e.g. `smapper:"-,compute:full_name"`).
+func WithComputes(computes ...*Compute) Option {
+	return func(mapper *Mapper) {
+		for _, compute := range computes {
+			mapper.computes[compute.Name] = compute
 		}
 	}
 }
@@ -13,7 +25,7 @@ func WithCallbacks(callbacks ...*Callback) Option {
 func WithValidators(validators ...*Validator) Option {
 	return func(mapper *Mapper) {
 		for _, validator := range validators {
-			mapper.validators[validator.Name] = validator.Func
+			mapper.validators[validator.Name] = validator.compiler()
 		}
 	}
 }
@@ -57,3 +69,164 @@ func WithAutoNumberToStringConversion() Option {
 		mapper.AutoNumberToStringConversion = true
 	}
 }
+
+// WithStrict if you set this option, you will get an error if a destination field has no matching source field.
+// fields with the ignore tag ("-") are skipped.
+func WithStrict() Option {
+	return func(mapper *Mapper) {
+		mapper.Strict = true
+	}
+}
+
+// WithStrictSource if you set this option, you will get an error if a source field is not read by any of
+// the destination fields.
+func WithStrictSource() Option {
+	return func(mapper *Mapper) {
+		mapper.StrictSource = true
+	}
+}
+
+// WithNamingStrategy if you set this option, fields are matched using the given naming strategy
+// (e.g. SnakeCase, CaseInsensitive, etc.) instead of their exact names, both field names and tag values
+// are converted using the strategy.
+func WithNamingStrategy(strategy NamingStrategy) Option {
+	return func(mapper *Mapper) {
+		mapper.naming = strategy
+	}
+}
+
+// WithStructNamingStrategy sets the naming strategy that is used whenever T is mapped (as the source or
+// the destination), it takes precedence over the strategy set by WithNamingStrategy.
+func WithStructNamingStrategy[T any](strategy NamingStrategy) Option {
+	return func(mapper *Mapper) {
+		mapper.structNaming[reflect.TypeOf((*T)(nil)).Elem()] = strategy
+	}
+}
+
+// WithTagName if you set this option, smapper reads the tag with the given key instead of "smapper".
+func WithTagName(name string) Option {
+	return func(mapper *Mapper) {
+		mapper.tagName = name
+	}
+}
+
+// WithProfile if you set this option, the fields that have a tag for the given profile (e.g. `smapper.db:"user_name"`
+// for the "db" profile) are mapped using that tag instead of their smapper tags, so a struct can be mapped from
+// different types with different rules.
+func WithProfile(name string) Option {
+	return func(mapper *Mapper) {
+		mapper.Profile = name
+	}
+}
+
+// WithTagFallbacks if you set this option, field names are taken from the given tags (in order) when the
+// smapper tag does not provide one (e.g. `json:"user_id,omitempty"`), modifiers like omitempty are ignored
+// and "-" ignores the field. the source fields are matched by the names in their fallback tags too, if no
+// source field has the name itself.
+func WithTagFallbacks(keys ...string) Option {
+	return func(mapper *Mapper) {
+		mapper.tagFallbacks = append(mapper.tagFallbacks, keys...)
+	}
+}
+
+// WithDefault sets the default value of the given field of T, it's used when the source field is missing or
+// zero, just like the default tag (e.g. `smapper:"status,default=active"`), and it takes precedence over it.
+// the value is converted to the field's type, and the mapping fails with a ConfigError if T has no exported
+// field with the given name.
+func WithDefault[T any](field string, value any) Option {
+	return func(mapper *Mapper) {
+		t := reflect.TypeOf((*T)(nil)).Elem()
+		if mapper.defaults[t] == nil {
+			mapper.defaults[t] = make(map[string]any)
+		}
+
+		mapper.defaults[t][field] = value
+	}
+}
+
+// WithMappingProfile applies the rules of a mapping profile (see LoadProfile), the rules replace the tags of the
+// fields they're defined for, but only when the fields are mapped from the profile's types. if several profiles
+// have rules for a field, the last one's rule is used. the callbacks and validators of the rules must be registered
+// in the mapper or its registry before the mapper is created, otherwise, the mapper returns a ConfigError.
+func WithMappingProfile(profile *MappingProfile) Option {
+	return func(mapper *Mapper) {
+		if profile == nil {
+			if mapper.optionErr == nil {
+				mapper.optionErr = &ConfigError{msg: "mapping profile cannot be nil"}
+			}
+
+			return
+		}
+
+		for pair, rules := range profile.rules {
+			if mapper.mappingRules[pair] == nil {
+				mapper.mappingRules[pair] = make(map[string]fieldRule)
+			}
+
+			for field, rule := range rules {
+				mapper.mappingRules[pair][field] = rule
+			}
+		}
+	}
+}
+
+// WithEnum registers an enum with the given name and values, then you can check if a field's value (or
+// each element of a slice) is one of the enum's values using the enum validator (e.g. `smapper:",enum=status"`).
+func WithEnum(name string, values ...any) Option {
+	return func(mapper *Mapper) {
+		mapper.enums[name] = append(mapper.enums[name], values...)
+	}
+}
+
+// WithMessages registers the message templates of the validators' errors, keyed by the validators' names,
+// templates can contain {field}, {path}, {param} and {value} placeholders (e.g. "{field} must be at least {param}").
+// the messages are returned by ValidationError.Message.
+func WithMessages(messages map[string]string) Option {
+	return func(mapper *Mapper) {
+		for name, msg := range messages {
+			mapper.messages[name] = msg
+		}
+	}
+}
+
+// WithStructValidator registers a validator for T, it's executed after a T is populated (including its nested
+// structs), just like the Validate method of the types that implement StructValidator.
+func WithStructValidator[T any](fn func(*T) error) Option {
+	return func(mapper *Mapper) {
+		mapper.structValidators[reflect.TypeOf((*T)(nil)).Elem()] = func(v reflect.Value) error {
+			return fn(v.Interface().(*T))
+		}
+	}
+}
+
+// WithBeforeMap registers a hook that is executed before an S is mapped into a D, including the nested structs,
+// it's executed before the BeforeMap method of D (see BeforeMapper). S can be a pointer to the source type.
+func WithBeforeMap[S, D any](fn func(src S, dst *D) error) Option {
+	return func(mapper *Mapper) {
+		key := hookKey[S, D]()
+
+		h := mapper.typeHooks[key]
+		h.before = append(h.before, newTypedHook(fn))
+		mapper.typeHooks[key] = h
+	}
+}
+
+// WithAfterMap registers a hook that is executed after an S is mapped into a D, including the nested structs,
+// it's executed before the AfterMap method of D (see AfterMapper), and before the struct validators.
+func WithAfterMap[S, D any](fn func(src S, dst *D) error) Option {
+	return func(mapper *Mapper) {
+		key := hookKey[S, D]()
+
+		h := mapper.typeHooks[key]
+		h.after = append(h.after, newTypedHook(fn))
+		mapper.typeHooks[key] = h
+	}
+}
+
+// WithRegistry sets the registry that the mapper looks up for the callbacks and validators that are not registered
+// in the mapper itself, it's DefaultRegistry by default, and it can be nil to ignore the registries.
+func WithRegistry(registry *Registry) Option {
+	return func(mapper *Mapper) {
+		mapper.registry = registry
+	}
+}
@@ -0,0 +1,683 @@
+package smapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// ConversionKind describes how a source field's value is converted to its destination field's type.
+type ConversionKind int
+
+const (
+	// ConversionNone means the destination field has no source field, so it's left untouched.
+	ConversionNone ConversionKind = iota
+	// ConversionDirect means the source and destination fields have the same type.
+	ConversionDirect
+	// ConversionNumeric means the value is converted between numeric types (e.g. int widening).
+	ConversionNumeric
+	// ConversionStringParse means a string is parsed into a number.
+	ConversionStringParse
+	// ConversionStringFormat means a number is formatted into a string.
+	ConversionStringFormat
+	// ConversionStruct means the nested struct is mapped recursively.
+	ConversionStruct
+	// ConversionSlice means each element of a slice or an array is converted.
+	ConversionSlice
+	// ConversionMap means each key and value of a map is converted.
+	ConversionMap
+	// ConversionCallback means the value is produced by a callback.
+	ConversionCallback
+	// ConversionDefault means the destination field has no source field, and it's filled with its default value.
+	ConversionDefault
+	// ConversionCompute means the value is computed using the whole source struct.
+	ConversionCompute
+	// ConversionUnsupported means there's no automatic conversion between the two types.
+	ConversionUnsupported
+)
+
+var conversionNames = map[ConversionKind]string{
+	ConversionNone:         "none",
+	ConversionDirect:       "direct",
+	ConversionNumeric:      "numeric",
+	ConversionStringParse:  "string parse",
+	ConversionStringFormat: "string format",
+	ConversionStruct:       "struct",
+	ConversionSlice:        "slice",
+	ConversionMap:          "map",
+	ConversionCallback:     "callback",
+	ConversionDefault:      "default",
+	ConversionCompute:      "compute",
+	ConversionUnsupported:  "unsupported",
+}
+
+func (k ConversionKind) String() string {
+	if name, found := conversionNames[k]; found {
+		return name
+	}
+
+	return fmt.Sprintf("ConversionKind(%d)", int(k))
+}
+
+// Plan describes what the mapper decided for each field of the destination type.
+type Plan struct {
+	Source      reflect.Type
+	Destination reflect.Type
+	Fields      []FieldPlan
+	// Unmapped contains the destination fields that have no source field.
+	Unmapped []string
+	// UnusedSource contains the source fields that are not read by any of the destination fields.
+	UnusedSource []string
+}
+
+// FieldPlan describes how a single destination field is filled.
+type FieldPlan struct {
+	// Name is the destination field's name.
+	Name string
+	// Source is the source field's name or its path if it's promoted from an embedded struct or it's nested,
+	// it's empty if the field is unmapped or ignored.
+	Source     string
+	Ignored    bool
+	Conversion ConversionKind
+	// Callback contains the names of the field's callbacks in the order they're executed, separated by commas.
+	Callback string
+	// Compute is the name of the compute function that computes the field, it's empty if the field is not computed.
+	Compute string
+	// Default is the value that is used when the source field is missing or zero, it's nil if there's no default.
+	Default any
+	// Validators contains the validators in the order they're executed.
+	Validators []string
+	// Nested is the plan of a nested struct (or the elements of a slice or map of structs).
+	Nested *Plan
+}
+
+// String renders the plan as a human-readable table.
+func (p *Plan) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s -> %s\n", p.Source, p.Destination)
+
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tSOURCE\tCONVERSION\tCALLBACK\tVALIDATORS")
+	p.writeRows(w, "")
+	_ = w.Flush()
+
+	if len(p.Unmapped) > 0 {
+		fmt.Fprintf(&sb, "unmapped: %s\n", strings.Join(p.Unmapped, ", "))
+	}
+
+	if len(p.UnusedSource) > 0 {
+		fmt.Fprintf(&sb, "unused source: %s\n", strings.Join(p.UnusedSource, ", "))
+	}
+
+	return sb.String()
+}
+
+func (p *Plan) writeRows(w *tabwriter.Writer, prefix string) {
+	for _, f := range p.Fields {
+		source, conversion, callback := f.Source, f.Conversion.String(), f.Callback
+		if f.Compute != "" {
+			callback = f.Compute
+		}
+
+		if f.Ignored {
+			source, conversion = "-", "ignored"
+		} else if source == "" {
+			source = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			prefix+f.Name,
+			source,
+			conversion,
+			orDash(callback),
+			orDash(strings.Join(f.Validators, ",")))
+
+		if f.Nested != nil {
+			nestedPrefix := prefix + f.Name + "."
+			if f.Conversion == ConversionSlice || f.Conversion == ConversionMap {
+				nestedPrefix = prefix + f.Name + "[]."
+			}
+
+			f.Nested.writeRows(w, nestedPrefix)
+		}
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// Explain returns the plan the given mapper uses to map Src to Dst, if mapper is nil, a mapper
+// with the default options is used.
+func Explain[Src, Dst any](mapper *Mapper) (*Plan, error) {
+	if mapper == nil {
+		mapper = New()
+	}
+
+	src := reflect.TypeOf((*Src)(nil)).Elem()
+	dst := reflect.TypeOf((*Dst)(nil)).Elem()
+
+	err := validateInputTypes(src, reflect.PointerTo(dst))
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+
+	return mapper.explain(src, dst, make(map[[2]reflect.Type]bool))
+}
+
+// explain builds the Plan of src to dst, visiting keeps the type pairs that are being explained,
+// so recursive types are explained only once.
+func (m *Mapper) explain(src, dst reflect.Type, visiting map[[2]reflect.Type]bool) (*Plan, error) {
+	sp, err := m.plan(src, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Plan{
+		Source:       src,
+		Destination:  dst,
+		Unmapped:     sp.unmapped,
+		UnusedSource: sp.unused,
+	}
+
+	visiting[[2]reflect.Type{src, dst}] = true
+	defer delete(visiting, [2]reflect.Type{src, dst})
+
+	for _, f := range sp.fields {
+		fp := FieldPlan{
+			Name:       f.name,
+			Source:     f.srcName,
+			Ignored:    f.ignored,
+			Conversion: f.conversion,
+			Callback:   f.opts.callbackNames(),
+		}
+
+		if f.opts.compute != nil {
+			fp.Compute = f.opts.compute.Name
+		}
+
+		if f.defaultValue.IsValid() {
+			fp.Default = f.defaultValue.Interface()
+		}
+
+		fp.Validators = f.opts.dive.names(f.opts.validators)
+
+		if nestedSrc, nestedDst, ok := nestedStructs(f); ok && !visiting[[2]reflect.Type{nestedSrc, nestedDst}] {
+			fp.Nested, err = m.explain(nestedSrc, nestedDst, visiting)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res.Fields = append(res.Fields, fp)
+	}
+
+	return res, nil
+}
+
+// nestedStructs returns the struct types that are mapped recursively for the given field.
+func nestedStructs(f fieldPlan) (reflect.Type, reflect.Type, bool) {
+	if f.srcType == nil {
+		return nil, nil, false
+	}
+
+	src, dst := f.srcType, f.dstType
+
+	switch f.conversion {
+	case ConversionSlice, ConversionMap:
+		src, dst = src.Elem(), dst.Elem()
+	case ConversionStruct:
+	default:
+		return nil, nil, false
+	}
+
+	if src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+
+	if src.Kind() != reflect.Struct || dst.Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+
+	return src, dst, true
+}
+
+// structPlan is the compiled mapping of a source struct type to a destination struct type.
+type structPlan struct {
+	fields []fieldPlan
+	// unmapped contains the destination fields without a source field
+	unmapped []string
+	// unused contains the source fields that are not read by any of the destination fields
+	unused []string
+	// hooks are executed before and after the fields are mapped
+	hooks hooks
+}
+
+type fieldPlan struct {
+	index      int
+	name       string
+	ignored    bool
+	srcIndex   []int
+	srcName    string
+	srcType    reflect.Type
+	dstType    reflect.Type
+	opts       fieldOptions
+	conversion ConversionKind
+	// defaultValue is the default value of the field that is already converted to the field's type
+	defaultValue reflect.Value
+	// validateNested is true if the field's type contains structs with struct validators
+	validateNested bool
+	// dstIndex is the index sequence of the destination field if it's promoted from an embedded struct,
+	// it's only used by the reverse plans, since the destination fields of the other plans are never promoted
+	dstIndex []int
+}
+
+// target returns the destination field, embedded pointers in the path of a promoted field are allocated if they're nil.
+func (f fieldPlan) target(dst FieldValue) reflect.Value {
+	if len(f.dstIndex) < 2 {
+		return dst.Field(f.index)
+	}
+
+	v := dst.Value
+	for i, index := range f.dstIndex {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(index)
+	}
+
+	return v
+}
+
+// sourceValue returns the source field's value, it returns false if the field has no source field, or
+// it's unreachable because it's promoted through a nil embedded pointer.
+func (f fieldPlan) sourceValue(src FieldValue) (reflect.Value, bool) {
+	if f.srcIndex == nil {
+		return reflect.Value{}, false
+	}
+
+	v, err := src.FieldByIndexErr(f.srcIndex)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
+
+// newDefault returns the default value of the field, pointers are copied, so mapped values never share them.
+func (f fieldPlan) newDefault() reflect.Value {
+	if f.defaultValue.Kind() != reflect.Ptr {
+		return f.defaultValue
+	}
+
+	v := reflect.New(f.defaultValue.Type().Elem())
+	v.Elem().Set(f.defaultValue.Elem())
+
+	return v
+}
+
+// compileValidators compiles the field's validators for the type of the values they validate, pre-conversion
+// validators validate the source field's type, or the destination field's type if the value is always the default
+// value, and post-conversion validators validate the destination field's type.
+// validators whose params are not valid for that type are reported as a ConfigError.
+func (f *fieldPlan) compileValidators(src, dst reflect.Type) error {
+	t := f.dstType
+	if f.srcType != nil {
+		t = f.srcType
+		// the value can be either the source value or the default value, so it's unknown until it's validated
+		if f.defaultValue.IsValid() && f.srcType != f.dstType {
+			t = nil
+		}
+	}
+
+	err := compileValidators(t, src, f.opts.validators, f.opts.dive, false)
+	if err == nil {
+		err = compileValidators(f.dstType, src, f.opts.validators, f.opts.dive, true)
+	}
+
+	if err != nil {
+		return &ConfigError{parentType: dst, fieldName: f.name, msg: err.Error()}
+	}
+
+	return nil
+}
+
+type planKey struct {
+	src    reflect.Type
+	dst    reflect.Type
+	config Config
+	// validation is true for the plans that are used by Mapper.Validate
+	validation bool
+	// registryVersion changes when a callback or a validator is registered in the mapper's registry
+	registryVersion uint64
+	// reverse is true for the plans that are used by Mapper.Reverse
+	reverse bool
+}
+
+// planCache keeps the compiled plans of a mapper, it's safe for concurrent use. it keeps only the plans of
+// the latest version of the mapper's registry, the plans of the older versions are dropped when a newer one
+// is stored, so registering in a long-lived registry doesn't grow the cache.
+type planCache struct {
+	mu      sync.RWMutex
+	version uint64
+	plans   map[planKey]*structPlan
+}
+
+func (c *planCache) load(key planKey) (*structPlan, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p, found := c.plans[key]
+
+	return p, found
+}
+
+func (c *planCache) store(key planKey, p *structPlan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case key.registryVersion < c.version:
+		// the plan is compiled while a newer version is stored, so it's already stale
+		return
+	case key.registryVersion > c.version || c.plans == nil:
+		c.version = key.registryVersion
+		c.plans = make(map[planKey]*structPlan)
+	}
+
+	c.plans[key] = p
+}
+
+// plan returns the compiled plan for mapping src to dst, plans are compiled once
+// and reused as long as the mapper's config is not changed.
+func (m *Mapper) plan(src, dst reflect.Type) (*structPlan, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.plans == nil && m.reverse {
+		return m.compileReversePlan(src, dst)
+	}
+
+	if m.plans == nil {
+		return m.compilePlan(src, dst)
+	}
+
+	key := planKey{src: src, dst: dst, config: m.Config, registryVersion: m.registryVersion(), reverse: m.reverse}
+	if p, found := m.plans.load(key); found {
+		return p, nil
+	}
+
+	compile := m.compilePlan
+	if m.reverse {
+		compile = m.compileReversePlan
+	}
+
+	p, err := compile(src, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	m.plans.store(key, p)
+
+	return p, nil
+}
+
+func (m *Mapper) compilePlan(src, dst reflect.Type) (*structPlan, error) {
+	if err := m.checkDefaults(dst); err != nil {
+		return nil, err
+	}
+
+	res := &structPlan{}
+	naming := m.namingStrategy(src, dst)
+
+	// keeps track of the top-level source fields that are read
+	used := make(map[int]bool)
+
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+
+		// ignores the unexported field
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldName := field.Name
+
+		// get the field name, callback function, and validator functions that
+		// need to be executed before setting the value
+		dstTags, err := m.parseTagValues(m.mappingTagValues(src, dst, field))
+		if err != nil {
+			return nil, err
+		}
+
+		fp := fieldPlan{
+			index:          i,
+			name:           field.Name,
+			dstType:        field.Type,
+			opts:           dstTags,
+			validateNested: m.hasStructValidators(field.Type, make(map[reflect.Type]bool)),
+		}
+
+		// computed fields have no source fields, even if they have names in their tags
+		if dstTags.compute != nil {
+			fp.conversion = ConversionCompute
+
+			if err := dstTags.compute.check(m.Config, src, dst, field.Type); err != nil {
+				return nil, &ConfigError{parentType: dst, fieldName: field.Name, msg: err.Error()}
+			}
+
+			// the source fields that the compute function reads are used, just like the mapped ones
+			for _, name := range dstTags.compute.Reads {
+				sf, found := findFieldPath(src, name, naming)
+				if !found {
+					msg := fmt.Sprintf("compute %s reads %s, but %s has no such field", dstTags.compute.Name, name, src.Name())
+					return nil, &ConfigError{parentType: dst, fieldName: field.Name, msg: msg}
+				}
+
+				used[sf.Index[0]] = true
+			}
+
+			if err := fp.compileValidators(src, dst); err != nil {
+				return nil, err
+			}
+
+			res.fields = append(res.fields, fp)
+
+			continue
+		}
+
+		if dstTags.field != emptyTag {
+			if dstTags.field == ignoreTag {
+				fp.ignored = true
+				res.fields = append(res.fields, fp)
+
+				continue
+			}
+
+			// use the provided field name in the field tag instead of the actual field name
+			fieldName = dstTags.field
+			if fieldName == dashTag {
+				fieldName = ignoreTag
+			}
+		}
+
+		fp.defaultValue, err = m.defaultValue(dst, field, dstTags.defaultValue)
+		if err != nil {
+			return nil, err
+		}
+
+		// search for the field (or the path of nested fields) in the input type, and ignore it if it's unexported,
+		// the source fields are matched by their fallback tags too
+		sf, found := findFieldPath(src, fieldName, naming)
+		if !found {
+			sf, found = m.findFallbackField(src, fieldName, naming)
+		}
+
+		if !found {
+			if fp.defaultValue.IsValid() {
+				fp.conversion = ConversionDefault
+			} else {
+				res.unmapped = append(res.unmapped, field.Name)
+			}
+
+			if err := fp.compileValidators(src, dst); err != nil {
+				return nil, err
+			}
+
+			res.fields = append(res.fields, fp)
+
+			continue
+		}
+
+		used[sf.Index[0]] = true
+
+		fp.srcIndex = sf.Index
+		fp.srcName = fieldPath(src, sf.Index)
+		fp.srcType = sf.Type
+		fp.conversion = conversionKind(m.Config, sf.Type, field.Type)
+		if len(dstTags.callbacks) > 0 {
+			fp.conversion = ConversionCallback
+
+			if err := checkCallbacks(m.Config, sf.Type, field.Type, dstTags.callbacks); err != nil {
+				return nil, &ConfigError{parentType: dst, fieldName: field.Name, msg: err.Error()}
+			}
+		}
+
+		if err := fp.compileValidators(src, dst); err != nil {
+			return nil, err
+		}
+
+		res.fields = append(res.fields, fp)
+	}
+
+	res.unused = unusedFields(src, used)
+	res.hooks = m.hooks(src, dst)
+
+	return res, nil
+}
+
+// checkCallbacks checks if the types of the typed callbacks match, the first callback receives a src, each callback
+// receives the previous one's output, and the last one's output is converted to dst.
+// the types are checked as long as they're known, the outputs of the callbacks that are not typed are unknown.
+func checkCallbacks(cfg Config, src, dst reflect.Type, callbacks []callback) error {
+	// interfaces are unknown types, because their dynamic types are known only when the callbacks are executed
+	known := func(t reflect.Type) bool {
+		return t != nil && t.Kind() != reflect.Interface
+	}
+
+	t := src
+
+	for i, c := range callbacks {
+		if c.in != nil && known(t) && !accepts(c.in, t) {
+			return fmt.Errorf("callback %s (step %d) wants %s, got %s", c.name, i+1, c.in, t)
+		}
+
+		t = c.out
+	}
+
+	if known(t) && t != dst && conversionKind(cfg, t, dst) == ConversionUnsupported {
+		return fmt.Errorf("cannot convert the output of callback %s (%s) to %s", callbacks[len(callbacks)-1].name, t, dst)
+	}
+
+	return nil
+}
+
+// accepts checks if a typed callback whose input type is in can receive a value of type t, the value is
+// asserted to in, so t must be in itself, or implement it if it's an interface.
+func accepts(in, t reflect.Type) bool {
+	if in.Kind() == reflect.Interface {
+		return t.Implements(in)
+	}
+
+	return t == in
+}
+
+// conversionKind returns the kind of conversion that is needed to convert src type to dst type, conversions
+// between strings and numbers are unsupported unless they're enabled in cfg.
+func conversionKind(cfg Config, src, dst reflect.Type) ConversionKind {
+	if src == dst {
+		return ConversionDirect
+	}
+
+	if src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+
+	switch {
+	case dst.Kind() == reflect.Map:
+		if src.Kind() == reflect.Map {
+			return ConversionMap
+		}
+	case dst.Kind() == reflect.Slice || dst.Kind() == reflect.Array:
+		if src.Kind() == reflect.Slice || src.Kind() == reflect.Array {
+			return ConversionSlice
+		}
+	case dst.Kind() == reflect.Struct:
+		if src.Kind() == reflect.Struct {
+			return ConversionStruct
+		}
+	case isNumber(dst.Kind()):
+		if isNumber(src.Kind()) {
+			return ConversionNumeric
+		}
+
+		if src.Kind() == reflect.String && cfg.AutoStringToNumberConversion {
+			return ConversionStringParse
+		}
+	case dst.Kind() == reflect.String:
+		if src.Kind() == reflect.String {
+			return ConversionDirect
+		}
+
+		if isNumber(src.Kind()) && cfg.AutoNumberToStringConversion {
+			return ConversionStringFormat
+		}
+	}
+
+	return ConversionUnsupported
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// fieldPath returns the dot separated names of the fields in the given index sequence.
+func fieldPath(t reflect.Type, index []int) string {
+	names := make([]string, 0, len(index))
+
+	for _, i := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		f := t.Field(i)
+		names = append(names, f.Name)
+		t = f.Type
+	}
+
+	return strings.Join(names, ".")
+}
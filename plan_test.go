@@ -0,0 +1,99 @@
+package smapper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City string
+	}
+
+	type src struct {
+		ID      int
+		Name    string
+		Age     string
+		Address address
+		Tags    []string
+		Secret  string
+	}
+
+	type dstAddress struct {
+		City string `smapper:",required"`
+	}
+
+	type dst struct {
+		ID       int64
+		Username string `smapper:"name,callback:upper,required"`
+		Age      int
+		Address  dstAddress
+		Tags     []string
+		Phone    string
+		Internal string `smapper:"-"`
+	}
+
+	mapper := New(WithCallbacks(NewCallback("upper", func(src, dst reflect.Type, v any) (any, error) {
+		return v, nil
+	})))
+
+	plan, err := Explain[src, dst](mapper)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"Phone"}, plan.Unmapped)
+	assert.Equal(t, []string{"Secret"}, plan.UnusedSource)
+
+	expected := []FieldPlan{
+		{Name: "ID", Source: "ID", Conversion: ConversionNumeric},
+		{Name: "Username", Source: "Name", Conversion: ConversionCallback, Callback: "upper", Validators: []string{"required"}},
+		// strings are not parsed into numbers unless AutoStringToNumberConversion is set
+		{Name: "Age", Source: "Age", Conversion: ConversionUnsupported},
+		{Name: "Address", Source: "Address", Conversion: ConversionStruct},
+		{Name: "Tags", Source: "Tags", Conversion: ConversionDirect},
+		{Name: "Phone", Conversion: ConversionNone},
+		{Name: "Internal", Ignored: true},
+	}
+
+	for i, f := range plan.Fields {
+		nested := f.Nested
+		f.Nested = nil
+
+		assert.Equal(t, expected[i], f)
+
+		if f.Name == "Address" {
+			assert.NotNil(t, nested)
+			assert.Equal(t, []string{"required"}, nested.Fields[0].Validators)
+		}
+	}
+
+	parsed, err := Explain[src, dst](mapper.With(WithAutoStringToNumberConversion()))
+	assert.NoError(t, err)
+	assert.Equal(t, ConversionStringParse, parsed.Fields[2].Conversion)
+
+	type formatted struct {
+		ID string
+	}
+
+	unsupported, err := Explain[src, formatted](nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ConversionUnsupported, unsupported.Fields[0].Conversion)
+
+	supported, err := Explain[src, formatted](New(WithAutoNumberToStringConversion()))
+	assert.NoError(t, err)
+	assert.Equal(t, ConversionStringFormat, supported.Fields[0].Conversion)
+
+	table := plan.String()
+	assert.Contains(t, table, "Address.City")
+	assert.Contains(t, table, "unmapped: Phone")
+
+	_, err = Explain[src, dst](New())
+	assert.Error(t, err, "should have error because 'upper' callback does not exist")
+
+	// recursive types should not be explained infinitely
+	plan, err = Explain[Complex, AnotherComplex](nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, plan.String())
+}
@@ -0,0 +1,90 @@
+package smapper
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Registry contains callbacks and validators that are shared by several mappers, it's safe for concurrent use.
+// a mapper looks up its registry only for the callbacks and validators that are not registered in the mapper
+// itself (using WithCallbacks and WithValidators), and just like them, registry validators can override
+// the built-in validators only if OverrideDefaultValidators is set.
+type Registry struct {
+	mu         sync.RWMutex
+	callbacks  map[string]*Callback
+	validators map[string]*Validator
+	// version is changed on each registration, so the mappers can compile their plans again
+	version atomic.Uint64
+}
+
+// DefaultRegistry is the registry of the mappers that are created without WithRegistry, including the ones that
+// are created by Map and MapTo, RegisterCallback and RegisterValidator register into it.
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{
+		callbacks:  make(map[string]*Callback),
+		validators: make(map[string]*Validator),
+	}
+}
+
+// RegisterCallback registers the callbacks in the registry, callbacks with the same names are replaced.
+func (r *Registry) RegisterCallback(callbacks ...*Callback) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, callback := range callbacks {
+		r.callbacks[callback.Name] = callback
+	}
+
+	r.version.Add(1)
+}
+
+// RegisterValidator registers the validators in the registry, validators with the same names are replaced.
+func (r *Registry) RegisterValidator(validators ...*Validator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, validator := range validators {
+		r.validators[validator.Name] = validator
+	}
+
+	r.version.Add(1)
+}
+
+func (r *Registry) callback(name string) (*Callback, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, found := r.callbacks[name]
+
+	return c, found
+}
+
+func (r *Registry) validator(name string) (*Validator, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, found := r.validators[name]
+
+	return v, found
+}
+
+// RegisterCallback registers the callbacks in the DefaultRegistry.
+func RegisterCallback(callbacks ...*Callback) {
+	DefaultRegistry.RegisterCallback(callbacks...)
+}
+
+// RegisterValidator registers the validators in the DefaultRegistry.
+func RegisterValidator(validators ...*Validator) {
+	DefaultRegistry.RegisterValidator(validators...)
+}
+
+// registryVersion returns the version of the mapper's registry, it's 0 if the mapper has no registry.
+func (m *Mapper) registryVersion() uint64 {
+	if m.registry == nil {
+		return 0
+	}
+
+	return m.registry.version.Load()
+}
@@ -0,0 +1,129 @@
+package smapper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegisterValidator(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Code string
+	}
+
+	type dst struct {
+		Code string `smapper:",registry_test_upper"`
+	}
+
+	RegisterValidator(NewValidator("registry_test_upper", func(v reflect.Value, param string) bool {
+		return v.String() == strings.ToUpper(v.String())
+	}))
+
+	// the package-level helpers use the DefaultRegistry
+	assert.NoError(t, Map(src{"ABC"}, &dst{}))
+	assert.Error(t, Map(src{"abc"}, &dst{}))
+
+	// mappers without a registry cannot find the validator
+	assert.Error(t, New(WithRegistry(nil)).Map(src{"ABC"}, &dst{}))
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Name string
+	}
+
+	type dst struct {
+		Name string `smapper:",callback:decorate,required"`
+	}
+
+	registry := NewRegistry()
+	decorate := func(prefix string) *Callback {
+		return NewCallback("decorate", func(src, dst reflect.Type, v any) (any, error) {
+			return prefix + v.(string), nil
+		})
+	}
+
+	first := New(WithRegistry(registry))
+	second := New(WithRegistry(registry))
+
+	// the callback is not registered yet, so the plan cannot be compiled
+	assert.Error(t, first.Map(src{"alice"}, &dst{}))
+
+	registry.RegisterCallback(decorate("registry:"))
+	registry.RegisterValidator(NewValidator("required", func(v reflect.Value, param string) bool {
+		return false
+	}))
+
+	for _, mapper := range []*Mapper{first, second} {
+		d := &dst{}
+		// registry validators cannot override the built-in validators without OverrideDefaultValidators
+		assert.NoError(t, mapper.Map(src{"alice"}, d))
+		assert.Equal(t, "registry:alice", d.Name)
+	}
+
+	// the plan is cached, registering again compiles a new plan that uses the new callback
+	registry.RegisterCallback(decorate("replaced:"))
+
+	d := &dst{}
+	assert.NoError(t, first.Map(src{"alice"}, d))
+	assert.Equal(t, "replaced:alice", d.Name)
+
+	// the plans of the old versions are dropped
+	assert.Len(t, first.plans.plans, 1)
+
+	// callbacks and validators that are registered in the mapper take precedence over the registry's
+	own := New(WithRegistry(registry), WithCallbacks(decorate("mapper:")))
+
+	d = &dst{}
+	assert.NoError(t, own.Map(src{"alice"}, d))
+	assert.Equal(t, "mapper:alice", d.Name)
+
+	overriding := New(WithRegistry(registry), WithOverrideDefaultValidators())
+	assert.Error(t, overriding.Map(src{"alice"}, &dst{}))
+}
+
+func TestRegistry_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Name string
+	}
+
+	type dst struct {
+		Name string `smapper:",callback:noop"`
+	}
+
+	registry := NewRegistry()
+	registry.RegisterCallback(NewCallback("noop", func(src, dst reflect.Type, v any) (any, error) {
+		return v, nil
+	}))
+
+	mapper := New(WithRegistry(registry))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, mapper.Map(src{"alice"}, &dst{}))
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			registry.RegisterValidator(NewValidator("noop", func(v reflect.Value, param string) bool {
+				return true
+			}))
+		}()
+	}
+
+	wg.Wait()
+}
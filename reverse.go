@@ -0,0 +1,252 @@
+package smapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Reverse maps the input back into the type it was mapped from, output must be a pointer to a struct.
+// the reverse mapping is derived from the tags that map output's type to input's type: renamed fields are
+// mapped back to their original names, ignored and computed fields are skipped, and the callbacks are
+// replaced by their inverses (see Callback.WithInverse) in the reverse order. validators and default values
+// are not reversed, but the struct validators and the lifecycle hooks of output's type are executed.
+// fields that cannot be reversed are left untouched, use CheckRoundTrip to find them.
+func (m *Mapper) Reverse(input, output any) error {
+	err := validateInputTypes(reflect.TypeOf(input), reflect.TypeOf(output))
+	if err != nil {
+		return err
+	}
+
+	srcVal := reflect.ValueOf(input)
+	if srcVal.Kind() == reflect.Ptr {
+		srcVal = srcVal.Elem()
+	}
+	dstVal := reflect.ValueOf(output).Elem()
+
+	reversed := *m
+	reversed.reverse = true
+
+	return reversed.mapTypes(FieldValue{Value: srcVal}, FieldValue{Value: dstVal, path: dstVal.Type().Name()})
+}
+
+// Reverse initializes a new Mapper with the provided options and executes the Mapper.Reverse.
+func Reverse(input, output any, opts ...Option) error {
+	mapper := New(opts...)
+
+	return mapper.Reverse(input, output)
+}
+
+// compileReversePlan compiles the plan for mapping src back to dst, using the plan of mapping dst to src.
+func (m *Mapper) compileReversePlan(src, dst reflect.Type) (*structPlan, error) {
+	forward := *m
+	forward.reverse = false
+
+	fp, err := forward.plan(dst, src)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &structPlan{unmapped: fp.unused}
+	used := make(map[int]bool)
+
+	for _, f := range fp.fields {
+		rf, reason := reverseField(m.Config, f)
+		if reason != "" {
+			if f.srcIndex != nil {
+				res.unmapped = append(res.unmapped, f.srcName)
+			}
+
+			continue
+		}
+
+		if rf.conversion == ConversionCallback {
+			if err := checkCallbacks(m.Config, rf.srcType, rf.dstType, rf.opts.callbacks); err != nil {
+				return nil, &ConfigError{parentType: dst, fieldName: rf.name, msg: err.Error()}
+			}
+		}
+
+		rf.validateNested = m.hasStructValidators(rf.dstType, make(map[reflect.Type]bool))
+		used[f.index] = true
+
+		res.fields = append(res.fields, rf)
+	}
+
+	res.unused = unusedFields(src, used)
+	res.hooks = m.hooks(src, dst)
+
+	return res, nil
+}
+
+// reverseField returns the reverse of a field of a forward plan, the reverse field reads the forward
+// destination field, and sets the forward source field. if the field cannot be reversed, the reason is returned.
+func reverseField(cfg Config, f fieldPlan) (fieldPlan, string) {
+	switch {
+	case f.ignored:
+		return fieldPlan{}, "ignored"
+	case f.opts.compute != nil:
+		return fieldPlan{}, fmt.Sprintf("computed by %s", f.opts.compute.Name)
+	case f.srcIndex == nil && f.defaultValue.IsValid():
+		return fieldPlan{}, "filled with its default value"
+	case f.srcIndex == nil:
+		return fieldPlan{}, "has no source field"
+	}
+
+	// the inverses are executed in the reverse order, each of them receives the same param as its callback
+	callbacks := make([]callback, 0, len(f.opts.callbacks))
+	for i := len(f.opts.callbacks) - 1; i >= 0; i-- {
+		c := f.opts.callbacks[i]
+		if c.inverse == nil {
+			return fieldPlan{}, fmt.Sprintf("callback %s has no inverse", c.name)
+		}
+
+		fn, _ := c.inverse.paramFunc()
+
+		callbacks = append(callbacks, callback{
+			name:  c.inverse.Name,
+			param: c.param,
+			fn:    fn,
+			in:    c.inverse.in,
+			out:   c.inverse.out,
+		})
+	}
+
+	res := fieldPlan{
+		index:    f.srcIndex[0],
+		dstIndex: f.srcIndex,
+		name:     f.srcName,
+		srcIndex: []int{f.index},
+		srcName:  f.name,
+		srcType:  f.dstType,
+		dstType:  f.srcType,
+		opts:     fieldOptions{callbacks: callbacks},
+	}
+
+	res.conversion = conversionKind(cfg, res.srcType, res.dstType)
+	if len(callbacks) > 0 {
+		res.conversion = ConversionCallback
+	} else if res.conversion == ConversionUnsupported {
+		return fieldPlan{}, fmt.Sprintf("cannot convert %s to %s", res.srcType, res.dstType)
+	}
+
+	return res, ""
+}
+
+// RoundTripReport describes the fields that don't survive mapping Src to Dst and reversing it back into Src.
+type RoundTripReport struct {
+	Source      reflect.Type
+	Destination reflect.Type
+	// Lost contains the source fields that are not restored by Reverse.
+	Lost []RoundTripIssue
+	// Dropped contains the destination fields that are not read by Reverse.
+	Dropped []RoundTripIssue
+}
+
+// RoundTripIssue describes why a field doesn't round-trip, the fields of nested structs are prefixed
+// with their parents' names.
+type RoundTripIssue struct {
+	Field  string
+	Reason string
+}
+
+// RoundTrips returns true if every field survives the round trip.
+func (r *RoundTripReport) RoundTrips() bool {
+	return len(r.Lost) == 0 && len(r.Dropped) == 0
+}
+
+// String renders the report as a human-readable list.
+func (r *RoundTripReport) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s <-> %s\n", r.Source, r.Destination)
+
+	for _, issue := range r.Lost {
+		fmt.Fprintf(&sb, "lost %s: %s\n", issue.Field, issue.Reason)
+	}
+
+	for _, issue := range r.Dropped {
+		fmt.Fprintf(&sb, "dropped %s: %s\n", issue.Field, issue.Reason)
+	}
+
+	return sb.String()
+}
+
+// CheckRoundTrip returns the report of the fields that don't round-trip when the given mapper maps Src to Dst,
+// and reverses Dst back into Src, nested structs are checked too. if mapper is nil, a mapper with the default
+// options is used.
+func CheckRoundTrip[Src, Dst any](mapper *Mapper) (*RoundTripReport, error) {
+	if mapper == nil {
+		mapper = New()
+	}
+
+	src := reflect.TypeOf((*Src)(nil)).Elem()
+	dst := reflect.TypeOf((*Dst)(nil)).Elem()
+
+	err := validateInputTypes(src, reflect.PointerTo(dst))
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+
+	res := &RoundTripReport{Source: src, Destination: dst}
+
+	err = mapper.checkRoundTrip(res, src, dst, "", "", make(map[[2]reflect.Type]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// checkRoundTrip adds the issues of src and dst to the report, the prefixes are the paths of the nested structs,
+// and visiting keeps the type pairs that are being checked, so recursive types are checked only once.
+func (m *Mapper) checkRoundTrip(
+	r *RoundTripReport,
+	src, dst reflect.Type,
+	srcPrefix, dstPrefix string,
+	visiting map[[2]reflect.Type]bool,
+) error {
+	sp, err := m.plan(src, dst)
+	if err != nil {
+		return err
+	}
+
+	visiting[[2]reflect.Type{src, dst}] = true
+	defer delete(visiting, [2]reflect.Type{src, dst})
+
+	for _, name := range sp.unused {
+		r.Lost = append(r.Lost, RoundTripIssue{Field: srcPrefix + name, Reason: "not mapped"})
+	}
+
+	for _, f := range sp.fields {
+		if _, reason := reverseField(m.Config, f); reason != "" {
+			if f.srcIndex != nil {
+				r.Lost = append(r.Lost, RoundTripIssue{Field: srcPrefix + f.srcName, Reason: reason})
+			}
+
+			r.Dropped = append(r.Dropped, RoundTripIssue{Field: dstPrefix + f.name, Reason: reason})
+
+			continue
+		}
+
+		nestedSrc, nestedDst, ok := nestedStructs(f)
+		if !ok || visiting[[2]reflect.Type{nestedSrc, nestedDst}] {
+			continue
+		}
+
+		suffix := "."
+		if f.conversion == ConversionSlice || f.conversion == ConversionMap {
+			suffix = "[]."
+		}
+
+		err := m.checkRoundTrip(r, nestedSrc, nestedDst, srcPrefix+f.srcName+suffix, dstPrefix+f.name+suffix, visiting)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
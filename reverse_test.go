@@ -0,0 +1,179 @@
+package smapper
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type reverseBase struct {
+	ID int
+}
+
+type reverseAddress struct {
+	City string
+}
+
+type reverseUser struct {
+	reverseBase
+	Name      string
+	Balance   int
+	Password  string
+	Addresses []reverseAddress
+}
+
+type reverseAddressDTO struct {
+	Town string `smapper:"City"`
+}
+
+type reverseUserDTO struct {
+	ID        int
+	FullName  string `smapper:"Name,callback:upper"`
+	Balance   string `smapper:",callback:scale=100"`
+	Password  string `smapper:"-"`
+	Addresses []reverseAddressDTO
+	Summary   string `smapper:",compute:summary"`
+}
+
+func reverseMapper() *Mapper {
+	upper := NewCallback("upper", func(src, dst reflect.Type, v any) (any, error) {
+		return strings.ToUpper(v.(string)), nil
+	}).WithInverse(NewCallback("lower", func(src, dst reflect.Type, v any) (any, error) {
+		return strings.ToLower(v.(string)), nil
+	}))
+
+	scale := NewParamCallback("scale", func(src, dst reflect.Type, v any, param string) (any, error) {
+		factor, _ := strconv.Atoi(param)
+
+		return strconv.Itoa(v.(int) * factor), nil
+	}).WithInverse(NewParamCallback("unscale", func(src, dst reflect.Type, v any, param string) (any, error) {
+		factor, _ := strconv.Atoi(param)
+		n, err := strconv.Atoi(v.(string))
+
+		return n / factor, err
+	}))
+
+	return New(
+		WithCallbacks(upper, scale),
+		WithComputes(NewCompute("summary", func(src, dst reflect.Value) (any, error) {
+			return fmt.Sprintf("%s (%d)", src.FieldByName("Name"), src.FieldByName("ID").Int()), nil
+		})),
+	)
+}
+
+func TestMapper_Reverse(t *testing.T) {
+	t.Parallel()
+
+	mapper := reverseMapper()
+
+	user := reverseUser{
+		reverseBase: reverseBase{ID: 7},
+		Name:        "alice",
+		Balance:     12,
+		Password:    "secret",
+		Addresses:   []reverseAddress{{"tehran"}, {"paris"}},
+	}
+
+	dto := &reverseUserDTO{}
+	assert.NoError(t, mapper.Map(user, dto))
+	assert.Equal(t, "ALICE", dto.FullName)
+	assert.Equal(t, "1200", dto.Balance)
+
+	res := &reverseUser{}
+	assert.NoError(t, mapper.Reverse(dto, res))
+
+	// the ignored fields are not restored
+	user.Password = ""
+	assert.Equal(t, user, *res)
+
+	// fields whose callbacks have no inverse are left untouched, and reported by strict mappers
+	lossy := New(WithCallbacks(NewCallback("upper", func(src, dst reflect.Type, v any) (any, error) {
+		return strings.ToUpper(v.(string)), nil
+	}), NewParamCallback("scale", func(src, dst reflect.Type, v any, param string) (any, error) {
+		return strconv.Itoa(v.(int)), nil
+	})), WithComputes(NewCompute("summary", func(src, dst reflect.Value) (any, error) {
+		return "", nil
+	})))
+
+	res = &reverseUser{Name: "unchanged"}
+	assert.NoError(t, lossy.Reverse(dto, res))
+	assert.Equal(t, "unchanged", res.Name)
+	assert.Equal(t, 7, res.ID)
+
+	var unmappedErr *UnmappedFieldsError
+	err := lossy.With(WithStrict()).Reverse(dto, &reverseUser{})
+	assert.ErrorAs(t, err, &unmappedErr)
+	assert.Contains(t, err.Error(), "Name")
+}
+
+func TestMapper_Reverse_InverseTypes(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Age int
+	}
+
+	type dst struct {
+		Age string `smapper:",callback:itoa"`
+	}
+
+	itoa := NewTypedCallback("itoa", func(v int) (string, error) {
+		return strconv.Itoa(v), nil
+	}).WithInverse(NewTypedCallback("atoi", func(v []string) (int, error) {
+		return len(v), nil
+	}))
+
+	var configErr *ConfigError
+	err := New(WithCallbacks(itoa)).Reverse(dst{"12"}, &src{})
+	assert.ErrorAs(t, err, &configErr)
+	assert.Contains(t, err.Error(), "callback atoi (step 1) wants []string, got string")
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	report, err := CheckRoundTrip[reverseUser, reverseUserDTO](reverseMapper())
+	assert.NoError(t, err)
+	assert.False(t, report.RoundTrips())
+	assert.Equal(t, []RoundTripIssue{{Field: "Password", Reason: "not mapped"}}, report.Lost)
+	assert.Equal(t, []RoundTripIssue{
+		{Field: "Password", Reason: "ignored"},
+		{Field: "Summary", Reason: "computed by summary"},
+	}, report.Dropped)
+
+	type address struct {
+		City string
+		Zip  string
+	}
+
+	type addressDTO struct {
+		City string `smapper:",callback:upper"`
+	}
+
+	type user struct {
+		Address address
+	}
+
+	type userDTO struct {
+		Location addressDTO `smapper:"Address"`
+	}
+
+	mapper := New(WithCallbacks(NewCallback("upper", func(src, dst reflect.Type, v any) (any, error) {
+		return strings.ToUpper(v.(string)), nil
+	})))
+
+	report, err = CheckRoundTrip[user, userDTO](mapper)
+	assert.NoError(t, err)
+	assert.Equal(t, []RoundTripIssue{
+		{Field: "Address.Zip", Reason: "not mapped"},
+		{Field: "Address.City", Reason: "callback upper has no inverse"},
+	}, report.Lost)
+	assert.Equal(t, []RoundTripIssue{{Field: "Location.City", Reason: "callback upper has no inverse"}}, report.Dropped)
+
+	report, err = CheckRoundTrip[address, address](nil)
+	assert.NoError(t, err)
+	assert.True(t, report.RoundTrips())
+}
@@ -0,0 +1,234 @@
+package smapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	uuidRegex     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-([0-9a-fA-F])[0-9a-fA-F]{3}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	numericRegex  = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+	hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+// stringValidators are the built-in string validators, unlike defaultValidators, they're replaced by the custom
+// validators with the same names even if OverrideDefaultValidators is not set, as those names were commonly used
+// by custom validators before they were built in.
+var stringValidators = map[string]validatorCompiler{
+	"email":     stringValidator("a valid email address", noParam(isEmail)),
+	"url":       stringValidator("a valid URL", noParam(isURL)),
+	"uri":       stringValidator("a valid URI", noParam(isURI)),
+	"uuid":      stringValidator("a valid UUID", compileUUID),
+	"ip":        stringValidator("a valid IP address", noParam(isIP)),
+	"ipv4":      stringValidator("a valid IPv4 address", noParam(isIPv4)),
+	"ipv6":      stringValidator("a valid IPv6 address", noParam(isIPv6)),
+	"cidr":      stringValidator("a valid CIDR notation", noParam(isCIDR)),
+	"hostname":  stringValidator("a valid hostname", noParam(isHostname)),
+	"regexp":    stringValidator("a string that matches {param}", compileRegex),
+	"alpha":     stringValidator("only ASCII letters", noParam(isAlpha)),
+	"alphanum":  stringValidator("only ASCII letters and numbers", noParam(isAlphanumeric)),
+	"numeric":   stringValidator("a numeric string", noParam(isNumeric)),
+	"ascii":     stringValidator("only ASCII characters", noParam(isASCII)),
+	"lowercase": stringValidator("a lowercase string", noParam(isLowercase)),
+	"uppercase": stringValidator("an uppercase string", noParam(isUppercase)),
+	"contains":  stringValidator("a string that contains {param}", withParam(strings.Contains)),
+	"prefix":    stringValidator("a string that starts with {param}", withParam(strings.HasPrefix)),
+	"suffix":    stringValidator("a string that ends with {param}", withParam(strings.HasSuffix)),
+	"json":      stringValidator("a valid JSON", noParam(isJSON)),
+}
+
+// regexCache keeps the compiled patterns of the regexp validator.
+var regexCache sync.Map
+
+// stringValue returns the string of v (or the string it points to), it returns false if v is not a string.
+func stringValue(v reflect.Value) (string, bool) {
+	v = reflect.Indirect(v)
+
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+
+	return v.String(), true
+}
+
+// stringValidator returns the compiler of a validator that accepts only strings, compile parses the param
+// and returns the function that checks the strings, desc describes the valid strings (e.g. "a valid email address"),
+// and it can contain the param (e.g. "a string that contains {param}").
+func stringValidator(desc string, compile func(param string) (func(string) bool, error)) validatorCompiler {
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		if t != nil && t.Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported type %s, want a string", t)
+		}
+
+		fn, err := compile(param)
+		if err != nil {
+			return nil, err
+		}
+
+		desc := strings.ReplaceAll(desc, "{param}", param)
+
+		return func(v, parent reflect.Value) error {
+			s, ok := stringValue(v)
+			if !ok {
+				return fmt.Errorf("unsupported type %s, want a string", v.Type())
+			}
+
+			if !fn(s) {
+				return fmt.Errorf("want %s, got %q", desc, s)
+			}
+
+			return nil
+		}, nil
+	}
+}
+
+// noParam is used for the string validators that have no params.
+func noParam(fn func(s string) bool) func(string) (func(string) bool, error) {
+	return func(param string) (func(string) bool, error) {
+		return fn, nil
+	}
+}
+
+// withParam is used for the string validators that use their params as they are (e.g. prefix=+98).
+func withParam(fn func(s, param string) bool) func(string) (func(string) bool, error) {
+	return func(param string) (func(string) bool, error) {
+		return func(s string) bool {
+			return fn(s, param)
+		}, nil
+	}
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+
+	// display names (e.g. "Admin <admin@example.com>") are not accepted
+	return err == nil && addr.Address == s
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isURI(s string) bool {
+	u, err := url.Parse(s)
+
+	return err == nil && u.Scheme != ""
+}
+
+// compileUUID compiles the uuid validator, the param is the uuid's version (e.g. uuid=4), it can be any
+// version if it's empty.
+func compileUUID(param string) (func(string) bool, error) {
+	if param != "" && (len(param) != 1 || !isHexDigit(rune(param[0]))) {
+		return nil, fmt.Errorf("invalid uuid version %s", param)
+	}
+
+	return func(s string) bool {
+		matches := uuidRegex.FindStringSubmatch(s)
+
+		return matches != nil && (param == "" || strings.EqualFold(matches[1], param))
+	}, nil
+}
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func isIP(s string) bool {
+	_, err := netip.ParseAddr(s)
+
+	return err == nil
+}
+
+func isIPv4(s string) bool {
+	addr, err := netip.ParseAddr(s)
+
+	return err == nil && addr.Is4()
+}
+
+func isIPv6(s string) bool {
+	addr, err := netip.ParseAddr(s)
+
+	return err == nil && addr.Is6()
+}
+
+func isCIDR(s string) bool {
+	_, err := netip.ParsePrefix(s)
+
+	return err == nil
+}
+
+// isHostname checks if s is a valid hostname according to RFC 1123.
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(s, ".") {
+		if !hostnameRegex.MatchString(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compileRegex compiles the pattern of the regexp validator, patterns are cached, so the fields that use
+// the same pattern share the compiled one.
+func compileRegex(param string) (func(string) bool, error) {
+	re, found := regexCache.Load(param)
+	if !found {
+		compiled, err := regexp.Compile(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s, %w", param, err)
+		}
+
+		re, _ = regexCache.LoadOrStore(param, compiled)
+	}
+
+	return re.(*regexp.Regexp).MatchString, nil
+}
+
+func isAlpha(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+	}) == -1
+}
+
+func isAlphanumeric(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	}) == -1
+}
+
+func isNumeric(s string) bool {
+	return numericRegex.MatchString(s)
+}
+
+func isASCII(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r > unicode.MaxASCII
+	}) == -1
+}
+
+func isLowercase(s string) bool {
+	return s != "" && s == strings.ToLower(s)
+}
+
+func isUppercase(s string) bool {
+	return s != "" && s == strings.ToUpper(s)
+}
+
+func isJSON(s string) bool {
+	return json.Valid([]byte(s))
+}
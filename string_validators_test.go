@@ -0,0 +1,105 @@
+package smapper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func Test_stringValidators(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		validator string
+		param     string
+		value     any
+		expected  bool
+	}
+
+	str := "admin@example.com"
+
+	tests := []testCase{
+		{"email", "", "admin@example.com", true},
+		{"email", "", &str, true},
+		{"email", "", "Admin <admin@example.com>", false},
+		{"email", "", "admin", false},
+		{"email", "", 42, false},
+		{"url", "", "https://example.com/path?q=1", true},
+		{"url", "", "example.com", false},
+		{"uri", "", "mailto:admin@example.com", true},
+		{"uri", "", "/relative/path", false},
+		{"uuid", "", "a3bb189e-8bf9-3888-9912-ace4e6543002", true},
+		{"uuid", "4", "9b2e3c3a-6a4e-4f7d-8b8c-2f1d3e4a5b6c", true},
+		{"uuid", "4", "a3bb189e-8bf9-3888-9912-ace4e6543002", false},
+		{"uuid", "", "not-a-uuid", false},
+		{"ip", "", "192.168.1.1", true},
+		{"ip", "", "::1", true},
+		{"ip", "", "256.1.1.1", false},
+		{"ipv4", "", "10.0.0.1", true},
+		{"ipv4", "", "::1", false},
+		{"ipv6", "", "2001:db8::1", true},
+		{"ipv6", "", "10.0.0.1", false},
+		{"cidr", "", "10.0.0.0/8", true},
+		{"cidr", "", "10.0.0.0", false},
+		{"hostname", "", "api.example.com", true},
+		{"hostname", "", "-invalid.com", false},
+		{"hostname", "", "under_score.com", false},
+		{"regexp", "^[a-z]{1,3}$", "abc", true},
+		{"regexp", "^[a-z]{1,3}$", "abcd", false},
+		{"regexp", "[", "abc", false},
+		{"alpha", "", "abcXYZ", true},
+		{"alpha", "", "abc1", false},
+		{"alphanum", "", "abc123", true},
+		{"alphanum", "", "abc-123", false},
+		{"numeric", "", "-3.14", true},
+		{"numeric", "", "3.", false},
+		{"ascii", "", "hello!", true},
+		{"ascii", "", "héllo", false},
+		{"lowercase", "", "hello", true},
+		{"lowercase", "", "Hello", false},
+		{"uppercase", "", "HELLO", true},
+		{"uppercase", "", "HELLo", false},
+		{"contains", "gmail", "admin@gmail.com", true},
+		{"contains", "gmail", "admin@yahoo.com", false},
+		{"prefix", "+98", "+98912", true},
+		{"prefix", "+98", "0912", false},
+		{"suffix", ".com", "example.com", true},
+		{"suffix", ".com", "example.org", false},
+		{"json", "", `{"id": 42}`, true},
+		{"json", "", `{"id": }`, false},
+		{"json", "", []byte(`{}`), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.validator+"/"+test.param, func(t *testing.T) {
+			// invalid params and unsupported types are reported when the validator is compiled
+			fn, err := stringValidators[test.validator](knownType(reflect.TypeOf(test.value)), nil, test.param)
+			valid := err == nil && fn(reflect.ValueOf(test.value), reflect.Value{}) == nil
+
+			assert.Equal(t, test.expected, valid, "value: %v", test.value)
+		})
+	}
+}
+
+func TestMap_StringValidators(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Email    string
+		Username string
+		Website  string
+	}
+
+	type dst struct {
+		Email    string `smapper:",required,email"`
+		Username string `smapper:",regexp='^[a-z]{3,16}$',lowercase"`
+		Website  string `smapper:",url,prefix=https://"`
+	}
+
+	mapper := New()
+
+	assert.NoError(t, mapper.Map(src{"admin@example.com", "admin", "https://example.com"}, &dst{}))
+	assert.Error(t, mapper.Map(src{"admin", "admin", "https://example.com"}, &dst{}))
+	assert.Error(t, mapper.Map(src{"admin@example.com", "ad", "https://example.com"}, &dst{}))
+	assert.Error(t, mapper.Map(src{"admin@example.com", "admin", "http://example.com"}, &dst{}))
+}
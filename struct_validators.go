@@ -0,0 +1,135 @@
+package smapper
+
+import "reflect"
+
+// StructValidator is implemented by the destination types that validate themselves, Validate is called
+// after all the fields of the struct (including the nested ones) are populated. Mapper.Validate doesn't call
+// the Validate method of the struct that is passed to it, so the method can call Mapper.Validate on the struct.
+type StructValidator interface {
+	Validate() error
+}
+
+// validateStruct executes the struct's Validate method and its registered validator (if there's any),
+// errors are wrapped in a ValidationError that contains the struct's path.
+func (m *Mapper) validateStruct(dst FieldValue) error {
+	target := dst.Value
+	if target.CanAddr() {
+		target = target.Addr()
+	}
+
+	if v, ok := target.Interface().(StructValidator); ok {
+		if err := v.Validate(); err != nil {
+			return &ValidationError{value: dst, validatorName: "Validate", err: err, structLevel: true}
+		}
+	}
+
+	return m.validateRegistered(dst)
+}
+
+// validateRegistered executes the struct validator that is registered for the struct's type using
+// WithStructValidator, if there's any.
+func (m *Mapper) validateRegistered(dst FieldValue) error {
+	target := dst.Value
+	if target.CanAddr() {
+		target = target.Addr()
+	}
+
+	if fn, found := m.structValidators[dst.Type()]; found && target.Kind() == reflect.Ptr {
+		if err := fn(target); err != nil {
+			return &ValidationError{value: dst, validatorName: "struct validator", err: err, structLevel: true}
+		}
+	}
+
+	return nil
+}
+
+// validateNested executes the struct validators of the structs in v, it's used for the values that are
+// assigned directly (without being mapped field by field), because their structs are not validated while mapping.
+func (m *Mapper) validateNested(v FieldValue) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return m.validateNested(v.From(v.Elem()))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || !m.hasStructValidators(field.Type, make(map[reflect.Type]bool)) {
+				continue
+			}
+
+			if err := m.validateNested(v.field(v.Field(i), field.Name)); err != nil {
+				return err
+			}
+		}
+
+		return m.validateStruct(v)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := m.validateNested(v.element(v.Index(i), i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values are not addressable, so a copy of them is validated
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+
+			if err := m.validateNested(v.element(value, iter.Key())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var structValidatorType = reflect.TypeOf((*StructValidator)(nil)).Elem()
+
+// hasStructValidators checks if t, or any of the types it contains, has a struct validator.
+func (m *Mapper) hasStructValidators(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return m.hasStructValidators(t.Elem(), visited)
+	case reflect.Map:
+		return m.hasStructValidators(t.Elem(), visited)
+	case reflect.Interface:
+		// the dynamic type is unknown
+		return true
+	case reflect.Struct:
+		if _, found := m.structValidators[t]; found || reflect.PointerTo(t).Implements(structValidatorType) {
+			return true
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && m.hasStructValidators(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// validateField executes the post-conversion validators of a field after its value is set, and the struct
+// validators of its value if it's assigned directly (without being mapped field by field).
+func (m *Mapper) validateField(f fieldPlan, value FieldValue, parent reflect.Value, assigned bool) error {
+	if err := validate(value, parent, f.opts.validators, f.opts.dive, true); err != nil {
+		return err
+	}
+
+	if !assigned || !f.validateNested {
+		return nil
+	}
+
+	return m.validateNested(value)
+}
@@ -1,11 +1,16 @@
 package smapper
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+)
 
 type FieldValue struct {
 	reflect.Value
 	ParentType reflect.Type
 	FieldName  string
+	// path is the location of the value in the destination struct (e.g. Order.Items[0].Address)
+	path string
 }
 
 func (f FieldValue) From(v reflect.Value) FieldValue {
@@ -13,9 +18,37 @@ func (f FieldValue) From(v reflect.Value) FieldValue {
 		Value:      v,
 		ParentType: f.ParentType,
 		FieldName:  f.FieldName,
+		path:       f.path,
 	}
 }
 
+// field returns the FieldValue of the given field of the struct f holds.
+func (f FieldValue) field(v reflect.Value, name string) FieldValue {
+	return FieldValue{
+		Value:      v,
+		ParentType: f.Type(),
+		FieldName:  name,
+		path:       f.path + "." + name,
+	}
+}
+
+// element returns the FieldValue of the given element (or map value) of the collection f holds.
+func (f FieldValue) element(v reflect.Value, key any) FieldValue {
+	res := f.From(v)
+	res.path = fmt.Sprintf("%s[%v]", f.path, key)
+
+	return res
+}
+
+// indexed returns the FieldValue of the given element (or map value) of the collection f holds, unlike
+// element, the index is added to the field's name too (e.g. Emails[2]).
+func (f FieldValue) indexed(v reflect.Value, key any) FieldValue {
+	res := f.element(v, key)
+	res.FieldName = fmt.Sprintf("%s[%v]", f.FieldName, key)
+
+	return res
+}
+
 func NewFieldValue(value reflect.Value, parent reflect.Type, name string) FieldValue {
 	return FieldValue{
 		Value:      value,
@@ -26,11 +59,25 @@ func NewFieldValue(value reflect.Value, parent reflect.Type, name string) FieldV
 
 type CallbackFunc func(reflect.Type, reflect.Type, any) (any, error)
 
+// ParamCallbackFunc is like CallbackFunc, but it also receives the param that is passed to the callback in
+// the field's tag (e.g. 64 in callback:truncate=64), the param is empty if it's not passed.
+type ParamCallbackFunc func(src reflect.Type, dst reflect.Type, v any, param string) (any, error)
+
 type ValidatorFunc func(reflect.Value, string) bool
 
+// CrossFieldValidatorFunc is like ValidatorFunc, but it also receives the source struct that contains
+// the field, so it can compare the field with the other fields.
+type CrossFieldValidatorFunc func(field reflect.Value, parent reflect.Value, param string) bool
+
+// ValidatorErrFunc is like CrossFieldValidatorFunc, but it returns an error that describes why the field
+// is not valid, the error is wrapped in the ValidationError that is returned by the mapper.
+type ValidatorErrFunc func(field reflect.Value, parent reflect.Value, param string) error
+
 type Validator struct {
-	Name string
-	Func ValidatorFunc
+	Name           string
+	Func           ValidatorFunc
+	CrossFieldFunc CrossFieldValidatorFunc
+	ErrFunc        ValidatorErrFunc
 }
 
 func NewValidator(name string, fn ValidatorFunc) *Validator {
@@ -40,9 +87,65 @@ func NewValidator(name string, fn ValidatorFunc) *Validator {
 	}
 }
 
+func NewCrossFieldValidator(name string, fn CrossFieldValidatorFunc) *Validator {
+	return &Validator{
+		Name:           name,
+		CrossFieldFunc: fn,
+	}
+}
+
+func NewErrValidator(name string, fn ValidatorErrFunc) *Validator {
+	return &Validator{
+		Name:    name,
+		ErrFunc: fn,
+	}
+}
+
+// compiler returns the compiler of the validator's function, custom validators are not aware of the types,
+// so their params are passed to them as they are.
+func (v *Validator) compiler() validatorCompiler {
+	fn := v.errFunc()
+
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		return func(field, parent reflect.Value) error {
+			return fn(field, parent, param)
+		}, nil
+	}
+}
+
+// errFunc returns the validator's function as a ValidatorErrFunc, a false result of the other functions
+// is reported as errValidationFailed.
+func (v *Validator) errFunc() ValidatorErrFunc {
+	if v.ErrFunc != nil {
+		return v.ErrFunc
+	}
+
+	fn := v.CrossFieldFunc
+	if fn == nil {
+		fn = func(field, parent reflect.Value, param string) bool {
+			return v.Func(field, param)
+		}
+	}
+
+	return func(field, parent reflect.Value, param string) error {
+		if !fn(field, parent, param) {
+			return errValidationFailed
+		}
+
+		return nil
+	}
+}
+
 type Callback struct {
-	Name string
-	Func CallbackFunc
+	Name      string
+	Func      CallbackFunc
+	ParamFunc ParamCallbackFunc
+	// Inverse converts the callback's output back to its input, it's used by Mapper.Reverse, and it receives
+	// the same param as the callback. fields whose callbacks have no inverse cannot be reversed.
+	Inverse *Callback
+	// in and out are the types of the input and the output of a typed callback, they're nil for the other callbacks
+	in  reflect.Type
+	out reflect.Type
 }
 
 func NewCallback(name string, fn CallbackFunc) *Callback {
@@ -51,3 +154,120 @@ func NewCallback(name string, fn CallbackFunc) *Callback {
 		Func: fn,
 	}
 }
+
+func NewParamCallback(name string, fn ParamCallbackFunc) *Callback {
+	return &Callback{
+		Name:      name,
+		ParamFunc: fn,
+	}
+}
+
+// WithInverse sets the callback's inverse and returns the callback, so it can be used inline.
+//
+//	WithCallbacks(NewCallback("cents", toCents).WithInverse(NewCallback("dollars", toDollars)))
+func (c *Callback) WithInverse(inverse *Callback) *Callback {
+	c.Inverse = inverse
+
+	return c
+}
+
+// paramFunc returns the callback's function as a ParamCallbackFunc, it returns false if the callback
+// doesn't accept params.
+func (c *Callback) paramFunc() (ParamCallbackFunc, bool) {
+	if c.ParamFunc != nil {
+		return c.ParamFunc, true
+	}
+
+	return func(src, dst reflect.Type, v any, param string) (any, error) {
+		return c.Func(src, dst, v)
+	}, false
+}
+
+// NewTypedCallback returns a callback that converts an S into a D, unlike NewCallback, fn doesn't need to
+// check the types, because they're checked when a mapping that uses the callback is planned, the mapping fails
+// with a ConfigError if the field's type is not S (or doesn't implement S if it's an interface), or D cannot be
+// converted to the destination field's type.
+func NewTypedCallback[S, D any](name string, fn func(S) (D, error)) *Callback {
+	in := reflect.TypeOf((*S)(nil)).Elem()
+
+	return &Callback{
+		Name: name,
+		Func: func(src, dst reflect.Type, v any) (any, error) {
+			s, ok := v.(S)
+			if !ok {
+				return nil, fmt.Errorf("want %s, got %T", in, v)
+			}
+
+			return fn(s)
+		},
+		in:  in,
+		out: reflect.TypeOf((*D)(nil)).Elem(),
+	}
+}
+
+// ComputeFunc computes a destination field's value using the whole source struct, dst is the destination
+// struct whose other fields are already mapped, except the computed fields that come after the field.
+type ComputeFunc func(src reflect.Value, dst reflect.Value) (any, error)
+
+// Compute is used for the destination fields that have no source field, their values are computed using
+// the whole source struct (e.g. `smapper:"-,compute:full_name"`).
+type Compute struct {
+	Name string
+	Func ComputeFunc
+	// Reads are the names of the source fields that the function reads, they're marked as used for StrictSource
+	Reads []string
+	// src, dst and out are the types of a typed compute function, they're nil for the other compute functions
+	src reflect.Type
+	dst reflect.Type
+	out reflect.Type
+}
+
+func NewCompute(name string, fn ComputeFunc) *Compute {
+	return &Compute{
+		Name: name,
+		Func: fn,
+	}
+}
+
+// NewTypedCompute returns a compute function that computes a V using an S source struct and a D destination struct,
+// the types are checked when a mapping that uses it is planned, the mapping fails with a ConfigError if the source
+// and destination types are not S and D, or V cannot be converted to the field's type.
+func NewTypedCompute[S, D, V any](name string, fn func(src S, dst *D) (V, error)) *Compute {
+	return &Compute{
+		Name: name,
+		Func: func(src, dst reflect.Value) (any, error) {
+			return fn(src.Interface().(S), dst.Addr().Interface().(*D))
+		},
+		src: reflect.TypeOf((*S)(nil)).Elem(),
+		dst: reflect.TypeOf((*D)(nil)).Elem(),
+		out: reflect.TypeOf((*V)(nil)).Elem(),
+	}
+}
+
+// WithReads sets the names (or dot separated paths) of the source fields that the compute function reads and
+// returns the compute function, so the fields are not reported as unused when StrictSource is set.
+//
+//	WithComputes(NewTypedCompute("full_name", fullName).WithReads("FirstName", "LastName"))
+func (c *Compute) WithReads(fields ...string) *Compute {
+	c.Reads = fields
+
+	return c
+}
+
+// check checks if the compute function can be used for computing a field of type field in dst, using src.
+func (c *Compute) check(cfg Config, src, dst, field reflect.Type) error {
+	if c.src != nil && c.src != src {
+		return fmt.Errorf("compute %s wants source %s, got %s", c.Name, c.src, src)
+	}
+
+	if c.dst != nil && c.dst != dst {
+		return fmt.Errorf("compute %s wants destination %s, got %s", c.Name, c.dst, dst)
+	}
+
+	if c.out != nil && c.out.Kind() != reflect.Interface && c.out != field &&
+		conversionKind(cfg, c.out, field) == ConversionUnsupported {
+		return fmt.Errorf("cannot convert the output of compute %s (%s) to %s", c.Name, c.out, field)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package smapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validate validates a struct (or a pointer to struct) using the validators in its tags, without mapping it
+// into another type. nested structs (including the ones in slices, arrays and maps) are validated using their
+// own tags, and the struct validators (see StructValidator and WithStructValidator) are executed too.
+// since there's no conversion, both pre and post-conversion validators validate the struct's values.
+// the Validate method of v itself is not called, so a struct's Validate method can call Validate on the struct
+// (e.g. func (u User) Validate() error { return smapper.Validate(u) }), the nested structs' methods are called.
+func (m *Mapper) Validate(v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return &Error{msg: fmt.Sprintf("input must be a struct or a pointer to struct, not %s", value.Kind())}
+	}
+
+	// struct validators may have pointer receivers, so the struct must be addressable
+	if !value.CanAddr() {
+		addressable := reflect.New(value.Type()).Elem()
+		addressable.Set(value)
+		value = addressable
+	}
+
+	root := FieldValue{Value: value, path: value.Type().Name()}
+	if err := m.validateFields(root); err != nil {
+		return err
+	}
+
+	return m.validateRegistered(root)
+}
+
+// Validate initializes a new Mapper with the given options and executes the Mapper.Validate.
+func Validate(v any, opts ...Option) error {
+	mapper := New(opts...)
+
+	return mapper.Validate(v)
+}
+
+// validateValue validates the fields of the struct in v, and the structs that v contains.
+func (m *Mapper) validateValue(v FieldValue) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return m.validateValue(v.From(v.Elem()))
+	case reflect.Struct:
+		if err := m.validateFields(v); err != nil {
+			return err
+		}
+
+		return m.validateStruct(v)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := m.validateValue(v.element(v.Index(i), i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values are not addressable, so a copy of them is validated
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+
+			if err := m.validateValue(v.element(value, iter.Key())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFields executes the validators of the struct's fields, then it validates the nested structs.
+func (m *Mapper) validateFields(v FieldValue) error {
+	plan, err := m.validationPlan(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range plan.fields {
+		if f.ignored {
+			continue
+		}
+
+		field := v.field(v.Field(f.index), f.name)
+
+		// the field's value is its own source, so ParentType is the struct's type, just like when it's mapped
+		if err := validate(field, v.Value, f.opts.validators, f.opts.dive, false); err != nil {
+			return err
+		}
+
+		if err := validate(field, v.Value, f.opts.validators, f.opts.dive, true); err != nil {
+			return err
+		}
+
+		if !f.validateNested {
+			continue
+		}
+
+		if err := m.validateValue(field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validationPlan returns the compiled plan for validating t, it's like a plan for mapping t into itself,
+// where each field is its own source.
+func (m *Mapper) validationPlan(t reflect.Type) (*structPlan, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.plans == nil {
+		return m.compileValidationPlan(t)
+	}
+
+	key := planKey{src: t, config: m.Config, validation: true, registryVersion: m.registryVersion()}
+	if p, found := m.plans.load(key); found {
+		return p, nil
+	}
+
+	p, err := m.compileValidationPlan(t)
+	if err != nil {
+		return nil, err
+	}
+
+	m.plans.store(key, p)
+
+	return p, nil
+}
+
+func (m *Mapper) compileValidationPlan(t reflect.Type) (*structPlan, error) {
+	res := &structPlan{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// ignores the unexported field
+		if !field.IsExported() {
+			continue
+		}
+
+		tags, err := m.parseTagValues(m.getTagValues(field))
+		if err != nil {
+			return nil, err
+		}
+
+		fp := fieldPlan{
+			index:   i,
+			name:    field.Name,
+			ignored: tags.field == ignoreTag,
+			srcType: field.Type,
+			dstType: field.Type,
+			opts:    tags,
+			// nested structs are validated using their own tags, so the field is validated if it contains any structs
+			validateNested: containsStruct(field.Type, make(map[reflect.Type]bool)),
+		}
+
+		if !fp.ignored {
+			if err := fp.compileValidators(t, t); err != nil {
+				return nil, err
+			}
+		}
+
+		res.fields = append(res.fields, fp)
+	}
+
+	return res, nil
+}
+
+// containsStruct checks if t is a struct, or it contains any structs.
+func containsStruct(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsStruct(t.Elem(), visited)
+	case reflect.Struct, reflect.Interface:
+		// the dynamic type of an interface is unknown
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,132 @@
+package smapper
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type signupAddress struct {
+	City    string `smapper:",required"`
+	ZipCode string `smapper:",numeric,len=5"`
+}
+
+type signupRequest struct {
+	Username        string          `smapper:",required,alphanum"`
+	Password        string          `smapper:",gte=8"`
+	ConfirmPassword string          `smapper:",eqfield=Password"`
+	Emails          []string        `smapper:",gte=1,dive,email"`
+	Addresses       []signupAddress `smapper:",lte=2"`
+	Billing         *signupAddress
+	Internal        string `smapper:"-"`
+}
+
+func TestMapper_Validate(t *testing.T) {
+	t.Parallel()
+
+	valid := func() signupRequest {
+		return signupRequest{
+			Username:        "alice",
+			Password:        "p4ssw0rd",
+			ConfirmPassword: "p4ssw0rd",
+			Emails:          []string{"alice@example.com"},
+			Addresses:       []signupAddress{{City: "Tehran", ZipCode: "12345"}},
+		}
+	}
+
+	type testCase struct {
+		name   string
+		modify func(r *signupRequest)
+		errMsg string
+	}
+
+	tests := []testCase{
+		{"valid", func(r *signupRequest) {}, ""},
+		{"missing username", func(r *signupRequest) { r.Username = "" }, "Username"},
+		{"short password", func(r *signupRequest) { r.Password, r.ConfirmPassword = "p4ss", "p4ss" }, "Password"},
+		{"passwords don't match", func(r *signupRequest) { r.ConfirmPassword = "password" }, "ConfirmPassword"},
+		{"invalid email", func(r *signupRequest) { r.Emails = append(r.Emails, "alice") }, "Emails[1]"},
+		{"invalid nested struct", func(r *signupRequest) { r.Addresses[0].ZipCode = "1234" }, "ZipCode"},
+		{"invalid nested pointer", func(r *signupRequest) { r.Billing = &signupAddress{ZipCode: "12345"} }, "City"},
+	}
+
+	mapper := New()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := valid()
+			test.modify(&r)
+
+			err := mapper.Validate(r)
+			if test.errMsg == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, mapper.Validate(&r))
+
+				return
+			}
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, err.Error(), test.errMsg)
+		})
+	}
+
+	assert.Error(t, mapper.Validate("alice"))
+}
+
+func TestMapper_Validate_StructValidators(t *testing.T) {
+	t.Parallel()
+
+	errTooManyGuests := errors.New("too many guests")
+
+	mapper := New(WithStructValidator(func(b *booking) error {
+		if b.Guests > 4 {
+			return errTooManyGuests
+		}
+
+		return nil
+	}))
+
+	assert.ErrorIs(t, mapper.Validate(booking{Guests: 5}), errTooManyGuests)
+	assert.ErrorContains(t, mapper.Validate(booking{Guests: 2, Periods: []period{{Start: 2, End: 1}}}),
+		"booking.Periods[0]")
+}
+
+type delegatingProfile struct {
+	Bio string `smapper:",lte=10"`
+}
+
+var errReservedBio = errors.New("bio is reserved")
+
+func (p delegatingProfile) Validate() error {
+	if p.Bio == "admin" {
+		return errReservedBio
+	}
+
+	return Validate(p)
+}
+
+type delegatingUser struct {
+	Name    string `smapper:",required"`
+	Profile delegatingProfile
+}
+
+func (u delegatingUser) Validate() error {
+	return Validate(u)
+}
+
+func TestMapper_Validate_DelegatingValidateMethod(t *testing.T) {
+	t.Parallel()
+
+	// the Validate methods call Validate on themselves, so the root's own method must not be called again
+	assert.NoError(t, delegatingUser{Name: "alice"}.Validate())
+	assert.ErrorContains(t, delegatingUser{}.Validate(), "delegatingUser.Name")
+
+	// the nested structs' methods are still called
+	err := delegatingUser{Name: "alice", Profile: delegatingProfile{Bio: "admin"}}.Validate()
+	assert.ErrorIs(t, err, errReservedBio)
+	assert.ErrorContains(t, err, "delegatingUser.Profile")
+
+	assert.ErrorContains(t, delegatingUser{Name: "alice", Profile: delegatingProfile{Bio: "a very long bio"}}.Validate(),
+		"delegatingProfile.Bio")
+}
@@ -2,153 +2,699 @@ package smapper
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
+const enumValidator = "enum"
+
+// errValidationFailed is returned by the validators that cannot describe their failures (e.g. ValidatorFunc).
+var errValidationFailed = errors.New("validation failed")
+
+// checkFunc validates a value, parent is the source struct that contains the validated field.
+type checkFunc func(v, parent reflect.Value) error
+
+// validatorCompiler parses the validator's param for the values of type t (nil if the type is unknown until
+// the validator is executed), parent is the type of the source struct, it returns the function that validates
+// the values, or an error if the param is invalid or the validator does not support t.
+type validatorCompiler func(t, parent reflect.Type, param string) (checkFunc, error)
+
+// phase specifies when a validator is executed.
+type phase int
+
+const (
+	// phaseDefault executes the validator in its default phase, which is phasePost for the comparison
+	// validators (see postValidators), and phasePre for the others
+	phaseDefault phase = iota
+	// phasePre executes the validator for the source value, before it's converted
+	phasePre
+	// phasePost executes the validator for the destination value, after it's converted and set
+	phasePost
+)
+
+// postValidators are the validators that are executed after the conversion by default, because their
+// results depend on the value's type (e.g. gte=18 compares the length of a string, but the value of an int).
+var postValidators = map[string]bool{
+	"eq":  true,
+	"ne":  true,
+	"gt":  true,
+	"gte": true,
+	"lt":  true,
+	"lte": true,
+}
+
 type validator struct {
-	name  string
-	param string
-	fn    ValidatorFunc
+	name    string
+	param   string
+	phase   phase
+	compile validatorCompiler
+	fn      checkFunc
+	// message is the template of the validator's error message, and key is its translation key
+	message string
+	key     string
+}
+
+// post checks if the validator is executed after the conversion.
+func (v validator) post() bool {
+	if v.phase == phaseDefault {
+		return postValidators[v.name]
+	}
+
+	return v.phase == phasePost
+}
+
+// dive contains the validators that are executed for each element (and key) of a slice, an array or a map.
+type dive struct {
+	keys       []validator
+	validators []validator
+	dive       *dive
+}
+
+// names returns the tag values of the validators including the dive ones (e.g. gte=1,dive,required).
+func (d *dive) names(validators []validator) []string {
+	var res []string
+
+	for _, v := range validators {
+		res = append(res, v.String())
+	}
+
+	if d == nil {
+		return res
+	}
+
+	res = append(res, diveTag)
+	if d.keys != nil {
+		res = append(res, keysTag)
+		res = append(res, (*dive)(nil).names(d.keys)...)
+		res = append(res, endKeysTag)
+	}
+
+	return append(res, d.dive.names(d.validators)...)
+}
+
+// compileValidators compiles the validators of the given phase for the values of type t, then it compiles
+// the dive validators for the elements (and keys) of t.
+func compileValidators(t, parent reflect.Type, validators []validator, d *dive, post bool) error {
+	t = knownType(t)
+
+	for i, v := range validators {
+		if v.post() != post {
+			continue
+		}
+
+		fn, err := v.compile(t, parent, v.param)
+		if err != nil {
+			return fmt.Errorf("invalid validator %s, %w", v, err)
+		}
+
+		validators[i].fn = fn
+	}
+
+	if !d.has(post) {
+		return nil
+	}
+
+	if t == nil {
+		return compileDive(nil, nil, parent, d, post)
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return compileDive(nil, t.Elem(), parent, d, post)
+	case reflect.Map:
+		return compileDive(t.Key(), t.Elem(), parent, d, post)
+	default:
+		return fmt.Errorf("cannot dive into %s", t)
+	}
 }
 
-var defaultValidators = map[string]ValidatorFunc{
-	"required": exists,
-	"unique":   isUnique,
-	"len":      hasLen,
-	"gte":      hasGte,
-	"gt":       hasGt,
-	"lte":      hasLte,
-	"lt":       hasLt,
-	"eq":       equals,
-	"ne":       notEquals,
+func compileDive(key, elem, parent reflect.Type, d *dive, post bool) error {
+	if err := compileValidators(key, parent, d.keys, nil, post); err != nil {
+		return err
+	}
+
+	return compileValidators(elem, parent, d.validators, d.dive, post)
 }
 
-func exists(v reflect.Value, param string) bool {
+// has checks if the dive (or its nested dives) has any validators of the given phase.
+func (d *dive) has(post bool) bool {
+	if d == nil {
+		return false
+	}
+
+	return hasPhase(d.keys, post) || hasPhase(d.validators, post) || d.dive.has(post)
+}
+
+func hasPhase(validators []validator, post bool) bool {
+	for _, v := range validators {
+		if v.post() == post {
+			return true
+		}
+	}
+
+	return false
+}
+
+// knownType returns the type that validators are compiled for, pointers are dereferenced, and it returns
+// nil for interfaces, because their types are unknown until the validators are executed.
+func knownType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t != nil && t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	return t
+}
+
+// validate executes the validators of the given phase for the given value, then it dives into its elements,
+// errors contain the index (or the key) of the element that is failed (e.g. Emails[2]).
+func validate(value FieldValue, parent reflect.Value, validators []validator, d *dive, post bool) error {
+	for _, v := range validators {
+		if v.post() != post {
+			continue
+		}
+
+		if err := v.fn(value.Value, parent); err != nil {
+			if errors.Is(err, errValidationFailed) {
+				err = nil
+			}
+
+			return &ValidationError{
+				value:         value,
+				validatorName: v.name,
+				param:         v.param,
+				err:           err,
+				message:       v.message,
+				key:           v.key,
+			}
+		}
+	}
+
+	if !d.has(post) {
+		return nil
+	}
+
+	collection := reflect.Indirect(value.Value)
+
+	switch collection.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < collection.Len(); i++ {
+			err := validate(value.indexed(collection.Index(i), i), parent, d.validators, d.dive, post)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := collection.MapRange()
+		for iter.Next() {
+			err := validate(value.indexed(iter.Key(), iter.Key()), parent, d.keys, nil, post)
+			if err != nil {
+				return err
+			}
+
+			err = validate(value.indexed(iter.Value(), iter.Key()), parent, d.validators, d.dive, post)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		if collection.IsValid() {
+			return &FieldError{
+				value: value,
+				msg:   fmt.Sprintf("cannot dive into %s", value.Type()),
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v validator) String() string {
+	res := v.name
+	if v.param != "" {
+		res += "=" + v.param
+	}
+
+	switch v.phase {
+	case phasePre:
+		return preTag + res
+	case phasePost:
+		return postTag + res
+	default:
+		return res
+	}
+}
+
+var defaultValidators = map[string]validatorCompiler{
+	"required": compileExists,
+	"unique":   compileUnique,
+	"len":      compileLen,
+	"gte":      compileCompare("greater than or equal to", func(res int) bool { return res >= 0 }),
+	"gt":       compileCompare("greater than", func(res int) bool { return res > 0 }),
+	"lte":      compileCompare("less than or equal to", func(res int) bool { return res <= 0 }),
+	"lt":       compileCompare("less than", func(res int) bool { return res < 0 }),
+	"eq":       compileEquals,
+	"ne":       compileNotEquals,
+	"oneof":    compileOneOf,
+}
+
+// errNil is returned when a validator that needs a value gets a nil pointer.
+var errNil = errors.New("value is nil")
+
+func exists(v reflect.Value) bool {
 	return !v.IsZero()
 }
 
-func isUnique(v reflect.Value, param string) bool {
-	value := reflect.ValueOf(struct{}{})
+func compileExists(t, parent reflect.Type, param string) (checkFunc, error) {
+	return func(v, parent reflect.Value) error {
+		if !exists(v) {
+			return errors.New("value is required")
+		}
+
+		return nil
+	}, nil
+}
+
+func compileUnique(t, parent reflect.Type, param string) (checkFunc, error) {
+	if t != nil {
+		if err := checkUnique(reflect.Zero(t)); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(v, parent reflect.Value) error {
+		v = reflect.Indirect(v)
+		if !v.IsValid() {
+			return errNil
+		}
+
+		if err := checkUnique(v); err != nil {
+			return err
+		}
+
+		if !isUnique(v) {
+			return errors.New("values are not unique")
+		}
+
+		return nil
+	}, nil
+}
 
+// checkUnique checks if the uniqueness of v's values can be checked.
+func checkUnique(v reflect.Value) error {
 	switch v.Kind() {
-	case reflect.Slice, reflect.Array:
+	case reflect.Slice, reflect.Array, reflect.Map:
 		el := v.Type().Elem()
 		if el.Kind() == reflect.Ptr {
 			el = el.Elem()
 		}
 
-		set := reflect.MakeMapWithSize(reflect.MapOf(el, value.Type()), v.Len())
-		for i := 0; i < v.Len(); i++ {
-			set.SetMapIndex(reflect.Indirect(v.Index(i)), value)
+		if !el.Comparable() {
+			return fmt.Errorf("cannot check the uniqueness of %s, its values are not comparable", v.Type())
 		}
 
-		return set.Len() == v.Len()
-	case reflect.Map:
-		iter := v.MapRange()
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for unique, want map, slice or array, got %s", v.Type())
+	}
+}
+
+func isUnique(v reflect.Value) bool {
+	value := reflect.ValueOf(struct{}{})
+
+	el := v.Type().Elem()
+	if el.Kind() == reflect.Ptr {
+		el = el.Elem()
+	}
 
-		keyType := v.Type().Elem()
-		if keyType.Kind() == reflect.Ptr {
-			keyType = keyType.Elem()
+	set := reflect.MakeMapWithSize(reflect.MapOf(el, value.Type()), v.Len())
+
+	// nil pointers are all equal, so they're counted as one value
+	nils := 0
+	add := func(e reflect.Value) {
+		if e.Kind() == reflect.Ptr && e.IsNil() {
+			nils = 1
+			return
 		}
 
-		set := reflect.MakeMapWithSize(reflect.MapOf(keyType, value.Type()), v.Len())
+		set.SetMapIndex(reflect.Indirect(e), value)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			add(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
 		for iter.Next() {
-			val := iter.Value()
-			set.SetMapIndex(reflect.Indirect(val), value)
+			add(iter.Value())
 		}
-
-		return set.Len() == v.Len()
-	default:
-		panic(fmt.Sprintf("unsupported type for unique, want map, slice or array got %s", v.Type().Name()))
 	}
+
+	return set.Len()+nils == v.Len()
 }
 
-func hasLen(v reflect.Value, param string) bool {
+func compileLen(t, parent reflect.Type, param string) (checkFunc, error) {
 	n, err := strconv.Atoi(param)
 	if err != nil {
-		panic(fmt.Sprintf("%s is not a valid number", param))
+		return nil, fmt.Errorf("%s is not a valid number", param)
 	}
-	l := v.Len()
-	return l == n
+
+	if t != nil && !hasLength(t.Kind()) && t.Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported type for len: %s", t)
+	}
+
+	return func(v, parent reflect.Value) error {
+		v = reflect.Indirect(v)
+		if !v.IsValid() {
+			return errNil
+		}
+
+		if !hasLength(v.Kind()) && v.Kind() != reflect.String {
+			return fmt.Errorf("unsupported type for len: %s", v.Type())
+		}
+
+		if v.Len() != n {
+			return fmt.Errorf("want length %d, got %d", n, v.Len())
+		}
+
+		return nil
+	}, nil
+}
+
+// numberParam is a param that is parsed for every kind of values that can be compared with it.
+type numberParam struct {
+	raw  string
+	i    int64
+	iErr error
+	u    uint64
+	uErr error
+	f    float64
+	fErr error
 }
 
-func compare(v reflect.Value, param string) int {
+func parseNumberParam(param string) numberParam {
+	p := numberParam{raw: param}
+
+	p.i, p.iErr = strconv.ParseInt(param, 10, 64)
+	p.u, p.uErr = strconv.ParseUint(param, 10, 64)
+	p.f, p.fErr = strconv.ParseFloat(param, 64)
+
+	return p
+}
+
+// compare compares v with the param, numbers are compared by their values, and strings, slices, arrays,
+// maps and channels are compared by their length.
+func (p numberParam) compare(v reflect.Value) (int, error) {
 	switch v.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		n, err := strconv.ParseInt(param, 10, 64)
-		if err != nil {
-			panic(fmt.Sprintf("invalid param, %s", err.Error()))
+		if p.iErr != nil {
+			return 0, fmt.Errorf("invalid param %s, want an integer", p.raw)
 		}
 
-		return cmp.Compare(v.Int(), n)
+		return cmp.Compare(v.Int(), p.i), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		n, err := strconv.ParseUint(param, 10, 64)
-		if err != nil {
-			panic(fmt.Sprintf("invalid param, %s", err.Error()))
+		if p.uErr != nil {
+			return 0, fmt.Errorf("invalid param %s, want an unsigned integer", p.raw)
 		}
 
-		return cmp.Compare(v.Uint(), n)
+		return cmp.Compare(v.Uint(), p.u), nil
 	case reflect.Float32, reflect.Float64:
-		n, err := strconv.ParseFloat(param, 64)
-		if err != nil {
-			panic(fmt.Sprintf("invalid param, %s", err.Error()))
+		if p.fErr != nil {
+			return 0, fmt.Errorf("invalid param %s, want a number", p.raw)
 		}
 
-		return cmp.Compare(v.Float(), n)
+		return cmp.Compare(v.Float(), p.f), nil
 	case reflect.Array, reflect.Slice, reflect.Map, reflect.Chan, reflect.String:
-		n, err := strconv.Atoi(param)
-		if err != nil {
-			panic(fmt.Sprintf("invalid param, %s", err.Error()))
+		if p.iErr != nil {
+			return 0, fmt.Errorf("invalid param %s, want a length", p.raw)
 		}
 
-		return cmp.Compare(v.Len(), n)
+		return cmp.Compare(int64(v.Len()), p.i), nil
 	default:
-		panic(fmt.Sprintf("unsupported type for compare: %s", v.Type().Name()))
+		return 0, fmt.Errorf("unsupported type for compare: %s", v.Type())
 	}
 }
 
-func hasGte(v reflect.Value, param string) bool {
-	return compare(v, param) >= 0
-}
+// describe returns what is compared in v, and its value (e.g. "length", 3).
+func describe(v reflect.Value) (string, any) {
+	if hasLength(v.Kind()) || v.Kind() == reflect.String {
+		return "length", v.Len()
+	}
 
-func hasGt(v reflect.Value, param string) bool {
-	return compare(v, param) > 0
+	return "value", v.Interface()
 }
 
-func hasLte(v reflect.Value, param string) bool {
-	return compare(v, param) <= 0
-}
+func compileCompare(desc string, ok func(int) bool) validatorCompiler {
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		p := parseNumberParam(param)
+
+		if t != nil {
+			if _, err := p.compare(reflect.Zero(t)); err != nil {
+				return nil, err
+			}
+		}
+
+		return func(v, parent reflect.Value) error {
+			v = reflect.Indirect(v)
+			if !v.IsValid() {
+				return errNil
+			}
+
+			res, err := p.compare(v)
+			if err != nil {
+				return err
+			}
+
+			if !ok(res) {
+				subject, value := describe(v)
+
+				return fmt.Errorf("want %s %s %s, got %v", subject, desc, param, value)
+			}
 
-func hasLt(v reflect.Value, param string) bool {
-	return compare(v, param) < 0
+			return nil
+		}, nil
+	}
 }
 
-func equals(v reflect.Value, param string) bool {
+// equals checks if v is equal to p, strings are compared by their values, and complex numbers are equal to
+// p if both of their real and imaginary parts are equal to it.
+func (p numberParam) equals(v reflect.Value) (bool, error) {
 	switch v.Kind() {
 	// since complex numbers have no ordering, we can just compare them for equality
 	case reflect.Complex64, reflect.Complex128:
-		n, err := strconv.ParseFloat(param, 64)
-		if err != nil {
-			panic(fmt.Sprintf("invalid param, %s", err.Error()))
+		if p.fErr != nil {
+			return false, fmt.Errorf("invalid param %s, want a number", p.raw)
 		}
 
-		return isComplexesEqual(v.Complex(), n)
+		return isComplexesEqual(v.Complex(), p.f), nil
 	case reflect.String:
-		return cmp.Compare(v.String(), param) == 0
+		return v.String() == p.raw, nil
 	}
 
-	return compare(v, param) == 0
+	res, err := p.compare(v)
+
+	return res == 0, err
 }
 
-func notEquals(v reflect.Value, param string) bool {
-	return !equals(v, param)
+func compileEquality(want bool) validatorCompiler {
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		p := parseNumberParam(param)
+
+		if t != nil {
+			if _, err := p.equals(reflect.Zero(t)); err != nil {
+				return nil, err
+			}
+		}
+
+		return func(v, parent reflect.Value) error {
+			v = reflect.Indirect(v)
+			if !v.IsValid() {
+				return errNil
+			}
+
+			equal, err := p.equals(v)
+			if err != nil {
+				return err
+			}
+
+			if equal == want {
+				return nil
+			}
+
+			subject, value := describe(v)
+			if v.Kind() == reflect.String {
+				subject, value = "value", v.String()
+			}
+
+			if want {
+				return fmt.Errorf("want %s equal to %s, got %v", subject, param, value)
+			}
+
+			return fmt.Errorf("want %s not equal to %s", subject, param)
+		}, nil
+	}
 }
 
+var (
+	compileEquals    = compileEquality(true)
+	compileNotEquals = compileEquality(false)
+)
+
 func isComplexesEqual(c complex128, n float64) bool {
-	a := cmp.Compare(real(c), n)
-	b := cmp.Compare(imag(c), n)
-	_, _ = a, b
 	return cmp.Compare(real(c), n) == 0 && cmp.Compare(imag(c), n) == 0
 }
+
+// eachElement executes fn for each element of v if it's a slice or an array, otherwise, it executes fn for v.
+func eachElement(v reflect.Value, fn func(reflect.Value) error) error {
+	v = reflect.Indirect(v)
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fn(v)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if err := fn(reflect.Indirect(v.Index(i))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// elementType returns the type of the elements of t if it's a slice or an array, otherwise, it returns t.
+func elementType(t reflect.Type) reflect.Type {
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		return knownType(t.Elem())
+	}
+
+	return t
+}
+
+// compileOneOf compiles the oneof validator, that checks if the value is one of the space separated
+// values in param (e.g. oneof=red green blue).
+func compileOneOf(t, parent reflect.Type, param string) (checkFunc, error) {
+	values := splitParams(param)
+	if len(values) == 0 {
+		return nil, errors.New("oneof needs at least one value")
+	}
+
+	if el := elementType(t); el != nil {
+		for _, value := range values {
+			if _, err := equalsParam(reflect.Zero(el), value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return func(v, parent reflect.Value) error {
+		return eachElement(v, func(v reflect.Value) error {
+			if !v.IsValid() {
+				return errNil
+			}
+
+			for _, value := range values {
+				equal, err := equalsParam(v, value)
+				if err != nil {
+					return err
+				}
+
+				if equal {
+					return nil
+				}
+			}
+
+			return fmt.Errorf("want one of [%s], got %v", strings.Join(values, ", "), v)
+		})
+	}, nil
+}
+
+// equalsParam checks if v is equal to the param, it returns an error if param is not a valid value for v's type.
+func equalsParam(v reflect.Value, param string) (bool, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String() == param, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid param %s, want an integer", param)
+		}
+
+		return v.Int() == n, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid param %s, want an unsigned integer", param)
+		}
+
+		return v.Uint() == n, nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid param %s, want a number", param)
+		}
+
+		return v.Float() == n, nil
+	default:
+		return false, fmt.Errorf("unsupported type %s, want a string or a number", v.Type())
+	}
+}
+
+// enumValidator returns the compiler of a validator that checks if the value is one of the values of
+// the given enum, enums are registered using WithEnum.
+func (m *Mapper) enumValidator(name string) (validatorCompiler, error) {
+	values, found := m.enums[name]
+	if !found {
+		return nil, &Error{msg: fmt.Sprintf("cannot find enum %s", name)}
+	}
+
+	return func(t, parent reflect.Type, param string) (checkFunc, error) {
+		return func(v, parent reflect.Value) error {
+			return eachElement(v, func(v reflect.Value) error {
+				for _, value := range values {
+					if sameValue(v, reflect.ValueOf(value)) {
+						return nil
+					}
+				}
+
+				return fmt.Errorf("want one of the values of enum %s, got %v", name, v)
+			})
+		}, nil
+	}, nil
+}
+
+// sameValue checks if a and b are equal, values of different types are equal if they have the same
+// kind of underlying value (e.g. a named string type and a string).
+func sameValue(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+
+	if a.Type() == b.Type() && a.Type().Comparable() {
+		return a.Equal(b)
+	}
+
+	switch {
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return a.String() == b.String()
+	case a.CanInt() && b.CanInt():
+		return a.Int() == b.Int()
+	case a.CanUint() && b.CanUint():
+		return a.Uint() == b.Uint()
+	case a.CanInt() && b.CanUint():
+		return a.Int() >= 0 && uint64(a.Int()) == b.Uint()
+	case a.CanUint() && b.CanInt():
+		return b.Int() >= 0 && a.Uint() == uint64(b.Int())
+	case a.CanFloat() && b.CanFloat():
+		return a.Float() == b.Float()
+	default:
+		return false
+	}
+}
@@ -1,7 +1,9 @@
 package smapper
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
 )
 
@@ -146,6 +148,18 @@ func Test_isUnique(t *testing.T) {
 			}
 		})
 	}
+
+	type ptrs struct {
+		Values []*string `smapper:",unique"`
+	}
+
+	a, b := "a", "b"
+
+	// nil pointers are counted as one value
+	assert.NoError(t, mapper.Map(ptrs{[]*string{&a, nil}}, &ptrs{}))
+	assert.NoError(t, mapper.Map(ptrs{[]*string{&a, &b, nil}}, &ptrs{}))
+	assert.Error(t, mapper.Map(ptrs{[]*string{&a, nil, nil}}, &ptrs{}))
+	assert.Error(t, mapper.Map(ptrs{[]*string{&a, &a, nil}}, &ptrs{}))
 }
 
 func Test_hasLen(t *testing.T) {
@@ -281,3 +295,308 @@ func makeChan(n int) chan struct{} {
 
 	return ch
 }
+
+func Test_isOneOf(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Color  string
+		Code   int
+		Ratio  float64
+		Colors []string
+	}
+
+	type dst struct {
+		Color  string   `smapper:",oneof=red green 'light blue'"`
+		Code   int      `smapper:",oneof=200 404 500"`
+		Ratio  float64  `smapper:",oneof=0.5 1"`
+		Colors []string `smapper:",oneof=red green"`
+	}
+
+	mapper := New()
+
+	assert.NoError(t, mapper.Map(src{"light blue", 404, 0.5, []string{"red", "red"}}, &dst{}))
+	assert.Error(t, mapper.Map(src{"blue", 404, 0.5, nil}, &dst{}), "blue is not one of the colors")
+	assert.Error(t, mapper.Map(src{"red", 201, 0.5, nil}, &dst{}), "201 is not one of the codes")
+	assert.Error(t, mapper.Map(src{"red", 200, 0.7, nil}, &dst{}), "0.7 is not one of the ratios")
+	assert.Error(t, mapper.Map(src{"red", 200, 1, []string{"red", "blue"}}, &dst{}), "blue is not one of the colors")
+}
+
+func Test_enum(t *testing.T) {
+	t.Parallel()
+
+	type status string
+
+	const (
+		statusActive   status = "active"
+		statusDisabled status = "disabled"
+	)
+
+	type src struct {
+		Status   string
+		Statuses []status
+		Priority int
+	}
+
+	type dst struct {
+		Status   status   `smapper:",enum=status"`
+		Statuses []status `smapper:",enum=status"`
+		Priority uint8    `smapper:",enum=priority"`
+	}
+
+	mapper := New(WithEnum("status", statusActive, statusDisabled), WithEnum("priority", 1, 2, 3))
+
+	assert.NoError(t, mapper.Map(src{"active", []status{statusDisabled}, 2}, &dst{}))
+	assert.Error(t, mapper.Map(src{"deleted", nil, 2}, &dst{}), "deleted is not a status")
+	assert.Error(t, mapper.Map(src{"active", []status{statusActive, "deleted"}, 2}, &dst{}), "deleted is not a status")
+	assert.Error(t, mapper.Map(src{"active", nil, 4}, &dst{}), "4 is not a priority")
+
+	assert.Error(t, New().Map(src{}, &dst{}), "should have error because the enums are not registered")
+}
+
+func Test_dive(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Emails []string
+		Scores map[string]int
+		Matrix [][]int
+	}
+
+	type dst struct {
+		Emails []string       `smapper:",gte=1,dive,required,email"`
+		Scores map[string]int `smapper:",dive,keys,alpha,endkeys,lte=100"`
+		Matrix [][]int        `smapper:",dive,len=2,dive,gt=0"`
+	}
+
+	mapper := New()
+
+	valid := func() src {
+		return src{
+			Emails: []string{"admin@example.com", "user@example.com"},
+			Scores: map[string]int{"math": 100, "art": 90},
+			Matrix: [][]int{{1, 2}, {3, 4}},
+		}
+	}
+
+	assert.NoError(t, mapper.Map(valid(), &dst{}))
+
+	s := valid()
+	s.Emails = nil
+	assert.Error(t, mapper.Map(s, &dst{}), "should have error because Emails must have at least one element")
+
+	s = valid()
+	s.Emails[1] = "invalid"
+	err := mapper.Map(s, &dst{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Emails[1]")
+
+	s = valid()
+	s.Scores["history"] = 101
+	err = mapper.Map(s, &dst{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Scores[history]")
+
+	s = valid()
+	s.Scores["math 2"] = 50
+	assert.Error(t, mapper.Map(s, &dst{}), "should have error because map keys must be alpha")
+
+	s = valid()
+	s.Matrix[1] = []int{3}
+	assert.Error(t, mapper.Map(s, &dst{}), "should have error because Matrix[1] must have 2 elements")
+
+	s = valid()
+	s.Matrix[1][0] = 0
+	err = mapper.Map(s, &dst{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Matrix[1][0]")
+
+	type invalidKeys struct {
+		Scores map[string]int `smapper:",dive,keys,alpha"`
+	}
+	assert.Error(t, mapper.Map(valid(), &invalidKeys{}), "should have error because keys are not closed")
+
+	type notCollection struct {
+		Emails int `smapper:",dive,required"`
+	}
+	assert.Error(t, mapper.Map(struct{ Emails int }{1}, &notCollection{}), "should have error because int has no elements")
+}
+
+type period struct {
+	Start int
+	End   int
+}
+
+func (p *period) Validate() error {
+	if p.End < p.Start {
+		return errors.New("end must be after start")
+	}
+
+	return nil
+}
+
+type booking struct {
+	Guests  int
+	Rooms   int
+	Periods []period
+}
+
+func TestMap_StructValidators(t *testing.T) {
+	t.Parallel()
+
+	mapper := New(WithStructValidator[booking](func(b *booking) error {
+		if b.Guests > b.Rooms*2 {
+			return errors.New("too many guests")
+		}
+
+		return nil
+	}))
+
+	valid := booking{Guests: 2, Rooms: 1, Periods: []period{{1, 2}, {3, 4}}}
+	assert.NoError(t, mapper.Map(valid, &booking{}))
+
+	invalidPeriod := booking{Guests: 2, Rooms: 1, Periods: []period{{1, 2}, {4, 3}}}
+	err := mapper.Map(invalidPeriod, &booking{})
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, err.Error(), "booking.Periods[1]")
+	assert.Contains(t, err.Error(), "end must be after start")
+
+	tooManyGuests := booking{Guests: 3, Rooms: 1}
+	err = mapper.Map(tooManyGuests, &booking{})
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, err.Error(), "too many guests")
+
+	assert.NoError(t, New().Map(tooManyGuests, &booking{}), "should be nil because the struct validator is not registered")
+}
+
+func TestMap_InvalidValidatorParams(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Name  string
+		Age   int
+		Email string
+	}
+
+	type badLen struct {
+		Name string `smapper:",len=abc"`
+	}
+
+	type badGte struct {
+		Age int `smapper:",gte=1.5"`
+	}
+
+	type badRegexp struct {
+		Name string `smapper:",regexp=["`
+	}
+
+	type badType struct {
+		Age int `smapper:",email"`
+	}
+
+	type badField struct {
+		Email string `smapper:",nefield=Username"`
+	}
+
+	type badDive struct {
+		Age int `smapper:",dive,required"`
+	}
+
+	mapper := New()
+
+	tests := map[string]any{
+		"len=abc":    &badLen{},
+		"gte=1.5":    &badGte{},
+		"regexp=[":   &badRegexp{},
+		"email":      &badType{},
+		"nefield":    &badField{},
+		"dive (int)": &badDive{},
+	}
+
+	for name, dst := range tests {
+		t.Run(name, func(t *testing.T) {
+			var configErr *ConfigError
+
+			assert.NotPanics(t, func() {
+				assert.ErrorAs(t, mapper.Map(src{"alireza", 24, "admin@example.com"}, dst), &configErr)
+			})
+		})
+	}
+}
+
+func TestMap_ErrValidators(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Username string
+	}
+
+	type dst struct {
+		Username string `smapper:",not_reserved=admin root"`
+	}
+
+	errReserved := errors.New("username is reserved")
+
+	mapper := New(WithValidators(NewErrValidator("not_reserved",
+		func(field, parent reflect.Value, param string) error {
+			for _, name := range splitParams(param) {
+				if field.String() == name {
+					return errReserved
+				}
+			}
+
+			return nil
+		})))
+
+	assert.NoError(t, mapper.Map(src{"alireza"}, &dst{}))
+
+	err := mapper.Map(src{"root"}, &dst{})
+	assert.ErrorIs(t, err, errReserved)
+	assert.EqualError(t, err, "smapper: validator not_reserved failed for src.Username, username is reserved")
+
+	// built-in validators describe their failures too
+	type lenDst struct {
+		Username string `smapper:",len=3"`
+	}
+
+	assert.EqualError(t, mapper.Map(src{"root"}, &lenDst{}),
+		"smapper: validator len failed for src.Username, want length 3, got 4")
+}
+
+func TestMap_ValidationPhases(t *testing.T) {
+	t.Parallel()
+
+	type src struct {
+		Age  string
+		Name string
+	}
+
+	type dst struct {
+		// gte is executed after Age is converted to an int, so it compares the number (not the string's length)
+		Age int `smapper:",gte=18"`
+		// pre:len validates the source string, post:gte validates the destination
+		Name string `smapper:",pre:len=5,post:gte=3"`
+	}
+
+	mapper := New(WithAutoStringToNumberConversion())
+
+	assert.NoError(t, mapper.Map(src{"24", "alice"}, &dst{}))
+
+	// the errors of both phases are reported for the same parent type
+	out := dst{Age: 30}
+	err := mapper.Map(src{"9", "alice"}, &out)
+	assert.EqualError(t, err, "smapper: validator gte failed for src.Age, want value greater than or equal to 18, got 9")
+	assert.EqualError(t, mapper.Map(src{"24", "al"}, &dst{}), "smapper: validator len failed for src.Name, want length 5, got 2")
+
+	// the field is set only if its post-conversion validators pass
+	assert.Equal(t, dst{Age: 30}, out)
+
+	assert.Error(t, mapper.Map(src{"24", "bob"}, &dst{}))
+
+	// the explicit phases are shown in the plan
+	plan, err := Explain[src, dst](mapper)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pre:len=5", "post:gte=3"}, plan.Fields[1].Validators)
+}